@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+)
+
+// GetAs returns the value of a key converted to T with the conversions used by Bind.
+// T can be any scalar type, a slice, a map, a struct with `cfg` tags, or one of the types
+// supported by Bind such as time.Duration or Secret.
+func GetAs[T any](g Getter, key string) (T, error) {
+	var value T
+	err := g.getAs(key, reflect.ValueOf(&value).Elem())
+	return value, err
+}
+
+// GetOr returns the value of a key converted to T, or def if the key does not exist or
+// cannot be converted.
+func GetOr[T any](g Getter, key string, def T) T {
+	value, err := GetAs[T](g, key)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// MustGet returns the value of a key converted to T, and panics if the key does not exist
+// or cannot be converted. It is meant for values that are guaranteed by defaults or a schema.
+func MustGet[T any](g Getter, key string) T {
+	value, err := GetAs[T](g, key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// getAs converts the value of a key into out.
+func (m *Manager) getAs(key string, out reflect.Value) error {
+	value, err := m.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return m.decodeValue("convert", key, value, out)
+}
+
+// getAs converts the value of a key into out under the read lock.
+func (t *ThreadSafeManager) getAs(key string, out reflect.Value) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.getAs(key, out)
+}
+
+// decodeValue decodes the value of a key into out, reporting errors and struct field
+// violations as a ConfigError for the given operation.
+func (m *Manager) decodeValue(operation, key string, value interface{}, out reflect.Value) error {
+	d := m.decoder()
+	if err := d.decode(key, value, out); err != nil {
+		// Keep the types of a sensitive value, but not the reason, which may reveal it.
+		var conversion *ConversionError
+		if key != "" && m.sensitiveKey(key) && errors.As(err, &conversion) {
+			err = &ConversionError{From: conversion.From, To: conversion.To}
+		}
+		return &ConfigError{
+			Operation: operation,
+			Key:       key,
+			Err:       err,
+		}
+	}
+
+	if len(d.violations) > 0 {
+		return &ConfigError{
+			Operation: operation,
+			Key:       key,
+			Err:       &ValidationError{Violations: d.violations},
+		}
+	}
+
+	return nil
+}
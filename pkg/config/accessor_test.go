@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGetAs(t *testing.T) {
+	m := New()
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"server": map[string]interface{}{
+			"host":    "localhost",
+			"port":    "8080",
+			"timeout": "30s",
+			"ratio":   0.5,
+			"debug":   1,
+			"tags":    []interface{}{"a", "b"},
+			"ports":   []interface{}{80, "443"},
+		},
+		"limits": map[string]interface{}{
+			"cpu":    2,
+			"memory": 512,
+		},
+	}))
+
+	port, err := GetAs[int](m, "server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	timeout, err := GetAs[time.Duration](m, "server.timeout")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	debug, err := GetAs[bool](m, "server.debug")
+	require.NoError(t, err)
+	assert.True(t, debug)
+
+	ports, err := GetAs[[]int](m, "server.ports")
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+
+	limits, err := GetAs[map[string]int](m, "limits")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, limits)
+
+	type server struct {
+		Host string   `cfg:"host"`
+		Port int      `cfg:"port"`
+		Tags []string `cfg:"tags"`
+	}
+	s, err := GetAs[server](m, "server")
+	require.NoError(t, err)
+	assert.Equal(t, server{Host: "localhost", Port: 8080, Tags: []string{"a", "b"}}, s)
+
+	_, err = GetAs[int](m, "server.host")
+	var configErr *ConfigError
+	require.True(t, errors.As(err, &configErr))
+	assert.Equal(t, "convert", configErr.Operation)
+	assert.Equal(t, "server.host", configErr.Key)
+
+	_, err = GetAs[int](m, "server.missing")
+	assert.Error(t, err)
+}
+
+func TestGetOr(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Set("port", "8080"))
+	require.NoError(t, m.Set("host", "localhost"))
+
+	assert.Equal(t, 8080, GetOr(m, "port", 80))
+	assert.Equal(t, 80, GetOr(m, "missing", 80))
+	assert.Equal(t, 80, GetOr(m, "host", 80))
+	assert.Equal(t, time.Minute, GetOr(m, "timeout", time.Minute))
+}
+
+func TestMustGet(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Set("port", 8080))
+
+	assert.Equal(t, int64(8080), MustGet[int64](m, "port"))
+	assert.Panics(t, func() { MustGet[int](m, "missing") })
+}
+
+func TestGetAs_ThreadSafeManager(t *testing.T) {
+	m := New().ThreadSafe()
+	require.NoError(t, m.Set("servers", []interface{}{"a", "b"}))
+
+	servers, err := GetAs[[]string](m, "servers")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, servers)
+	assert.Equal(t, "a", GetOr(m, "servers[0]", ""))
+}
+
+func TestManager_GetBool_Numbers(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Set("enabled", int64(1)))
+	require.NoError(t, m.Set("disabled", uint(0)))
+
+	enabled, err := m.GetBool("enabled")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	disabled, err := m.GetBool("disabled")
+	require.NoError(t, err)
+	assert.False(t, disabled)
+}
+
+func TestWithStrictConversion(t *testing.T) {
+	data := map[string]interface{}{
+		"ratio":    3.9,
+		"whole":    4.0,
+		"garbage":  "12abc",
+		"padded":   " 12 ",
+		"big":      int64(1<<53 + 1),
+		"flag":     int64(2),
+		"enabled":  uint(1),
+		"small":    300,
+		"overflow": "99999999999999999999",
+		"octal":    "010",
+		"hex":      "0x1F",
+		"grouped":  "1_000",
+		"password": 3.9,
+	}
+
+	lenient := New()
+	require.NoError(t, lenient.MergeMap(data))
+
+	ratio, err := lenient.GetInt("ratio")
+	require.NoError(t, err)
+	assert.Equal(t, 3, ratio, "lenient conversions truncate floats")
+
+	padded, err := lenient.GetInt("padded")
+	require.NoError(t, err)
+	assert.Equal(t, 12, padded)
+
+	garbage, err := lenient.GetInt("garbage")
+	require.NoError(t, err)
+	assert.Equal(t, 12, garbage, "lenient conversions read the number a string starts with")
+
+	_, err = GetAs[int8](lenient, "small")
+	assert.Error(t, err, "lenient conversions should reject values out of range")
+
+	_, err = lenient.GetInt64("overflow")
+	assert.Error(t, err, "lenient conversions should reject numeric strings out of range")
+
+	flag, err := lenient.GetBool("flag")
+	require.NoError(t, err)
+	assert.True(t, flag)
+
+	strict := New(WithStrictConversion(true), WithSensitiveKeys("password"))
+	require.NoError(t, strict.MergeMap(data))
+
+	octal, err := strict.GetInt("octal")
+	require.NoError(t, err)
+	assert.Equal(t, 10, octal, "integers are parsed in base 10")
+
+	whole, err := strict.GetInt("whole")
+	require.NoError(t, err)
+	assert.Equal(t, 4, whole)
+
+	enabled, err := strict.GetBool("enabled")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	tests := []struct {
+		key    string
+		get    func(key string) error
+		from   string
+		to     string
+		reason string
+	}{
+		{"ratio", func(key string) error { _, err := strict.GetInt(key); return err }, "float64", "int", "3.9 has a fractional part"},
+		{"garbage", func(key string) error { _, err := strict.GetInt(key); return err }, "string", "int", `"12abc" is not a number`},
+		{"padded", func(key string) error { _, err := strict.GetInt(key); return err }, "string", "int", `" 12 " is not a number`},
+		{"big", func(key string) error { _, err := strict.GetFloat(key); return err }, "int64", "float64", "9007199254740993 cannot be represented exactly"},
+		{"flag", func(key string) error { _, err := strict.GetBool(key); return err }, "int64", "bool", "2 is neither 0 nor 1"},
+		{"small", func(key string) error { _, err := GetAs[int8](strict, key); return err }, "int", "int8", "300 is out of range"},
+		{"hex", func(key string) error { _, err := strict.GetInt(key); return err }, "string", "int", `"0x1F" is not a number`},
+		{"grouped", func(key string) error { _, err := GetAs[uint](strict, key); return err }, "string", "uint", `"1_000" is not a number`},
+		{"overflow", func(key string) error { _, err := strict.GetInt64(key); return err }, "string", "int64", "99999999999999999999 is out of range"},
+		{"password", func(key string) error { _, err := strict.GetInt(key); return err }, "float64", "int", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := tt.get(tt.key)
+
+			var configErr *ConfigError
+			require.True(t, errors.As(err, &configErr))
+			assert.Equal(t, tt.key, configErr.Key)
+
+			var conversionErr *ConversionError
+			require.True(t, errors.As(err, &conversionErr))
+			assert.Equal(t, tt.from, conversionErr.From.String())
+			assert.Equal(t, tt.to, conversionErr.To.String())
+			assert.Equal(t, tt.reason, conversionErr.Reason)
+		})
+	}
+
+	type limits struct {
+		Ratio int `cfg:"ratio"`
+	}
+	var l limits
+	assert.Error(t, strict.Bind(&l), "Bind should use strict conversions too")
+}
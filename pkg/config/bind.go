@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkRules evaluates a comma-separated list of validation rules against value and
+// returns a message for every rule that is not satisfied. Supported rules are required,
+// min=N, max=N and oneof=a b c. For strings, slices and maps min and max apply to the length.
+// Pointers are dereferenced, and a nil pointer is only checked by required.
+func checkRules(value reflect.Value, rules string) []string {
+	var messages []string
+
+	required := value.IsValid() && !value.IsZero()
+	for value.IsValid() && value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "":
+		case "required":
+			if !required {
+				messages = append(messages, "value is required")
+			}
+		case "min", "max":
+			if !value.IsValid() {
+				continue
+			}
+
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("invalid %s rule %q", name, param))
+				continue
+			}
+
+			measure, kind, ok := measureValue(value)
+			if !ok {
+				messages = append(messages, fmt.Sprintf("%s rule is not supported for %s", name, value.Type()))
+				continue
+			}
+
+			if name == "min" && measure < limit {
+				messages = append(messages, fmt.Sprintf("%s %v is less than min %v", kind, measure, limit))
+			} else if name == "max" && measure > limit {
+				messages = append(messages, fmt.Sprintf("%s %v is greater than max %v", kind, measure, limit))
+			}
+		case "oneof":
+			if !value.IsValid() {
+				continue
+			}
+
+			actual := fmt.Sprint(value.Interface())
+			allowed := strings.Fields(param)
+			found := false
+			for _, option := range allowed {
+				if option == actual {
+					found = true
+					break
+				}
+			}
+			if !found {
+				messages = append(messages, fmt.Sprintf("value %q is not one of %v", actual, allowed))
+			}
+		default:
+			messages = append(messages, fmt.Sprintf("unknown validation rule %q", name))
+		}
+	}
+
+	return messages
+}
+
+// measureValue returns the number used by min and max rules: the value of numbers and the
+// length of strings, slices and maps.
+func measureValue(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "value", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "value", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "value", true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), "length", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), "length", true
+	default:
+		return 0, "", false
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_BindTags(t *testing.T) {
+	type Server struct {
+		Host    string        `json:"host" validate:"required"`
+		Port    int           `json:"port" default:"8080" validate:"min=1,max=65535"`
+		Timeout time.Duration `json:"timeout" default:"30s"`
+	}
+
+	type Config struct {
+		Server Server   `json:"server"`
+		Level  string   `json:"level" default:"info" validate:"oneof=debug info warn"`
+		Tags   []string `json:"tags" default:"a,b" validate:"min=1"`
+	}
+
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{"server": {"host": "localhost"}}`), FormatJSON))
+
+	var cfg Config
+	require.NoError(t, m.Bind(&cfg))
+	assert.Equal(t, Config{
+		Server: Server{Host: "localhost", Port: 8080, Timeout: 30 * time.Second},
+		Level:  "info",
+		Tags:   []string{"a", "b"},
+	}, cfg, "defaults should fill missing keys")
+
+	require.NoError(t, m.Load(strings.NewReader(`{"server": {"port": 70000}, "level": "trace", "tags": []}`), FormatJSON))
+	err := m.Bind(&Config{})
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []Violation{
+		{Key: "server.host", Message: "field Host: value is required"},
+		{Key: "server.port", Message: "field Port: value 70000 is greater than max 65535"},
+		{Key: "level", Message: `field Level: value "trace" is not one of [debug info warn]`},
+		{Key: "tags", Message: "field Tags: length 0 is less than min 1"},
+	}, validationErr.Violations)
+}
+
+func TestManager_BindOptionalPointers(t *testing.T) {
+	type Config struct {
+		Level   *string `cfg:"level" validate:"oneof=debug info"`
+		Workers *int    `cfg:"workers" validate:"min=1,max=8"`
+		Name    *string `cfg:"name" validate:"required"`
+	}
+
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{"name": "app"}`), FormatJSON))
+
+	var cfg Config
+	require.NoError(t, m.Bind(&cfg), "rules other than required should skip nil pointers")
+	assert.Nil(t, cfg.Level)
+	assert.Nil(t, cfg.Workers)
+
+	require.NoError(t, m.Load(strings.NewReader(`{"level": "info", "workers": 4, "name": "app"}`), FormatJSON))
+	require.NoError(t, m.Bind(&cfg))
+	assert.Equal(t, "info", *cfg.Level)
+	assert.Equal(t, 4, *cfg.Workers)
+
+	require.NoError(t, m.Load(strings.NewReader(`{"level": "trace", "workers": 0}`), FormatJSON))
+	err := m.Bind(&Config{})
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []Violation{
+		{Key: "level", Message: `field Level: value "trace" is not one of [debug info]`},
+		{Key: "workers", Message: "field Workers: value 0 is less than min 1"},
+		{Key: "name", Message: "field Name: value is required"},
+	}, validationErr.Violations)
+}
+
+func TestManager_BindDecoder(t *testing.T) {
+	type Common struct {
+		Name string `cfg:"name"`
+	}
+
+	type Level int
+
+	type Config struct {
+		Common  `cfg:",squash"`
+		Timeout time.Duration     `cfg:"timeout"`
+		Cache   ByteSize          `cfg:"cache_size"`
+		Bind    net.IP            `cfg:"bind"`
+		Network net.IPNet         `cfg:"network"`
+		Backend url.URL           `cfg:"backend"`
+		Match   *regexp.Regexp    `cfg:"match"`
+		Level   Level             `cfg:"level"`
+		Labels  map[string]string `cfg:"labels"`
+		Ports   []uint16          `cfg:"ports"`
+		Keep    string            `cfg:"keep,omitempty"`
+		Ignored string            `cfg:"-"`
+	}
+
+	levels := map[string]Level{"debug": 1, "info": 2}
+	hook := func(value interface{}, target reflect.Type) (interface{}, bool, error) {
+		if s, ok := value.(string); ok && target == reflect.TypeOf(Level(0)) {
+			return int(levels[s]), true, nil
+		}
+		return nil, false, nil
+	}
+
+	m := New(WithCaseSensitive(false), WithDecodeHook(hook))
+	require.NoError(t, m.Load(strings.NewReader(`
+name: app
+Timeout: 1m30s
+cache_size: 10MiB
+bind: 10.0.0.1
+network: 10.0.0.0/8
+backend: https://api.example.com/v1
+match: ^user-[0-9]+$
+level: info
+labels: {team: core}
+ports: [80, "443"]
+keep: ""
+ignored: value
+`), FormatYAML))
+
+	cfg := Config{Keep: "kept"}
+	require.NoError(t, m.Bind(&cfg))
+
+	assert.Equal(t, "app", cfg.Name, "squashed structs should decode from the same map")
+	assert.Equal(t, 90*time.Second, cfg.Timeout, "keys should match case-insensitively")
+	assert.Equal(t, 10*Mebibyte, cfg.Cache)
+	assert.Equal(t, "10.0.0.1", cfg.Bind.String())
+	assert.Equal(t, "10.0.0.0/8", cfg.Network.String())
+	assert.Equal(t, "api.example.com", cfg.Backend.Host)
+	assert.True(t, cfg.Match.MatchString("user-42"))
+	assert.Equal(t, Level(2), cfg.Level, "decode hooks should convert values")
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	assert.Equal(t, []uint16{80, 443}, cfg.Ports)
+	assert.Empty(t, cfg.Keep, "omitempty should not change how a field is decoded")
+	assert.Empty(t, cfg.Ignored)
+
+	strict := New()
+	require.NoError(t, strict.Load(strings.NewReader(`{"Timeout": "1s"}`), FormatJSON))
+	var cs Config
+	require.NoError(t, strict.Bind(&cs))
+	assert.Zero(t, cs.Timeout, "tagged keys should match case-sensitively by default")
+
+	assert.Error(t, m.Bind(cfg), "Bind should require a pointer")
+}
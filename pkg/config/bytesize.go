@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Byte size units. Decimal units (KB, MB, ...) are powers of 1000 and binary units
+// (KiB, MiB, ...) are powers of 1024.
+const (
+	Byte     ByteSize = 1
+	Kilobyte ByteSize = 1000 * Byte
+	Megabyte ByteSize = 1000 * Kilobyte
+	Gigabyte ByteSize = 1000 * Megabyte
+	Terabyte ByteSize = 1000 * Gigabyte
+	Petabyte ByteSize = 1000 * Terabyte
+	Kibibyte ByteSize = 1024 * Byte
+	Mebibyte ByteSize = 1024 * Kibibyte
+	Gibibyte ByteSize = 1024 * Mebibyte
+	Tebibyte ByteSize = 1024 * Gibibyte
+	Pebibyte ByteSize = 1024 * Tebibyte
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   Kilobyte,
+	"kb":  Kilobyte,
+	"m":   Megabyte,
+	"mb":  Megabyte,
+	"g":   Gigabyte,
+	"gb":  Gigabyte,
+	"t":   Terabyte,
+	"tb":  Terabyte,
+	"p":   Petabyte,
+	"pb":  Petabyte,
+	"ki":  Kibibyte,
+	"kib": Kibibyte,
+	"mi":  Mebibyte,
+	"mib": Mebibyte,
+	"gi":  Gibibyte,
+	"gib": Gibibyte,
+	"ti":  Tebibyte,
+	"tib": Tebibyte,
+	"pi":  Pebibyte,
+	"pib": Pebibyte,
+}
+
+// ParseByteSize parses a byte size such as "1024", "10MB", "512KiB" or "1.5 GB".
+// Units are case-insensitive.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if split < 0 {
+		split = len(s)
+	}
+
+	number, unit := s[:split], strings.ToLower(strings.TrimSpace(s[split:]))
+	if number == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit %q in %q", unit, s)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	size := value * float64(multiplier)
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q overflows uint64", s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// String formats the size with the largest binary unit that divides it exactly.
+func (b ByteSize) String() string {
+	units := []struct {
+		size ByteSize
+		name string
+	}{
+		{Pebibyte, "PiB"},
+		{Tebibyte, "TiB"},
+		{Gibibyte, "GiB"},
+		{Mebibyte, "MiB"},
+		{Kibibyte, "KiB"},
+	}
+
+	for _, unit := range units {
+		if b >= unit.size && b%unit.size == 0 {
+			return fmt.Sprintf("%d%s", b/unit.size, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%dB", uint64(b))
+}
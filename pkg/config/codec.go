@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	codecs     = make(map[Format]Codec)
+	extensions = make(map[string]Format)
+)
+
+func init() {
+	registerCodec(FormatJSON, jsonCodec{})
+	registerCodec(FormatYAML, yamlCodec{})
+	registerCodec(FormatTOML, tomlCodec{})
+
+	// FormatYML shares the YAML codec, but files with a .yml extension keep
+	// being detected as FormatYAML.
+	codecs[FormatYML] = yamlCodec{}
+}
+
+// RegisterCodec makes a codec available to every Manager under the given format.
+// The codec's extensions are used by LoadFile to detect the format of a file.
+// Registering a codec for an existing format replaces the previous codec.
+func RegisterCodec(format Format, codec Codec) error {
+	if format == "" {
+		return &ConfigError{
+			Operation: "register codec",
+			Err:       errors.New("format cannot be empty"),
+		}
+	}
+
+	if codec == nil {
+		return &ConfigError{
+			Operation: "register codec",
+			Err:       fmt.Errorf("codec for format '%s' cannot be nil", format),
+		}
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registerCodec(format, codec)
+
+	return nil
+}
+
+// WithCodec registers a codec for the given format on a single Manager.
+// Codecs registered this way take precedence over the global registry.
+func WithCodec(format Format, codec Codec) Option {
+	return func(m *Manager) {
+		if format == "" || codec == nil {
+			return
+		}
+
+		if m.codecs == nil {
+			m.codecs = make(map[Format]Codec)
+			m.extensions = make(map[string]Format)
+		}
+
+		m.codecs[format] = codec
+		for _, ext := range codec.Extensions() {
+			m.extensions[normalizeExtension(ext)] = format
+		}
+	}
+}
+
+// registerCodec adds a codec and its extensions to the global registry.
+// The caller must hold registryMu.
+func registerCodec(format Format, codec Codec) {
+	codecs[format] = codec
+	for _, ext := range codec.Extensions() {
+		extensions[normalizeExtension(ext)] = format
+	}
+}
+
+// codec returns the codec for the given format, preferring codecs registered on the Manager.
+func (m *Manager) codec(format Format) (Codec, error) {
+	if codec, ok := m.codecs[format]; ok {
+		return codec, nil
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if codec, ok := codecs[format]; ok {
+		return codec, nil
+	}
+
+	return nil, fmt.Errorf("unsupported file format: %s", format)
+}
+
+// formatForExtension returns the format registered for a file extension,
+// preferring codecs registered on the Manager.
+func (m *Manager) formatForExtension(ext string) (Format, bool) {
+	if format, ok := m.extensions[ext]; ok {
+		return format, true
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	format, ok := extensions[ext]
+	return format, ok
+}
+
+// normalizeExtension lower-cases an extension and strips its leading dot.
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// jsonCodec implements Codec for JSON documents.
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(content []byte) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, errors.New("unexpected JSON structure")
+	}
+
+	return data, nil
+}
+
+func (jsonCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	return json.MarshalIndent(data, "", "  ")
+}
+
+func (jsonCodec) Extensions() []string {
+	return []string{"json"}
+}
+
+func (jsonCodec) positions(content []byte) (map[string]position, error) {
+	return jsonPositions(content)
+}
+
+// yamlCodec implements Codec for YAML documents.
+type yamlCodec struct{}
+
+func (yamlCodec) Decode(content []byte) (map[string]interface{}, error) {
+	var data interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	if mapData, ok := data.(map[string]interface{}); ok {
+		return mapData, nil
+	}
+
+	if mapData, ok := data.(map[interface{}]interface{}); ok {
+		if strMap, ok := transformMapKeys(mapData).(map[string]interface{}); ok {
+			return strMap, nil
+		}
+		return nil, errors.New("failed to convert YAML data to map[string]interface{}")
+	}
+
+	return nil, errors.New("unexpected YAML structure")
+}
+
+func (yamlCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(data)
+}
+
+func (yamlCodec) Extensions() []string {
+	return []string{"yaml", "yml"}
+}
+
+func (yamlCodec) positions(content []byte) (map[string]position, error) {
+	return yamlPositions(content)
+}
+
+// tomlCodec implements Codec for TOML documents.
+type tomlCodec struct{}
+
+func (tomlCodec) Decode(content []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if _, err := toml.Decode(string(content), &data); err != nil {
+		return nil, err
+	}
+
+	if strMap, ok := transformTOMLValues(data).(map[string]interface{}); ok {
+		return strMap, nil
+	}
+
+	return nil, errors.New("failed to convert TOML data to map[string]interface{}")
+}
+
+// Encode writes whole floats, such as the numbers decoded from JSON, as integers. Null
+// values cannot be represented in TOML and are rejected rather than dropped.
+func (tomlCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	values, err := tomlValues(data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (tomlCodec) Extensions() []string {
+	return []string{"toml"}
+}
+
+// tomlValues returns a copy of v, found at key, ready for the TOML encoder.
+func tomlValues(v interface{}, key string) (interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("TOML cannot represent the null value of key '%s'", key)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			converted, err := tomlValues(val, joinKey(key, k))
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i, val := range x {
+			converted, err := tomlValues(val, joinKey(key, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	case float64:
+		if x == math.Trunc(x) && x >= math.MinInt64 && x < math.MaxInt64 {
+			return int64(x), nil
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// propertiesCodec is a minimal key=value codec used to exercise custom codecs.
+type propertiesCodec struct{}
+
+func (propertiesCodec) Decode(content []byte) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		data[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return data, nil
+}
+
+func (propertiesCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	lines := make([]string, 0, len(data))
+	for k, v := range data {
+		lines = append(lines, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (propertiesCodec) Extensions() []string {
+	return []string{"properties"}
+}
+
+func TestWithCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.properties")
+	require.NoError(t, os.WriteFile(path, []byte("name = CodecApp\nport = 9000\n"), 0644))
+
+	err := New().LoadFile(path)
+	assert.Error(t, err, "LoadFile should reject extensions without a registered codec")
+
+	m := New(WithCodec("properties", propertiesCodec{}))
+	require.NoError(t, m.LoadFile(path))
+
+	port, err := m.GetInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, port)
+
+	require.NoError(t, m.Set("name", "Renamed"))
+	require.NoError(t, m.SaveToFile(path, "properties"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name=Renamed\nport=9000", string(content))
+}
+
+func TestRegisterCodec(t *testing.T) {
+	assert.Error(t, RegisterCodec("", propertiesCodec{}), "RegisterCodec should reject an empty format")
+	assert.Error(t, RegisterCodec("properties", nil), "RegisterCodec should reject a nil codec")
+
+	format, err := detectFileFormat("config.yml", New().formatForExtension)
+	require.NoError(t, err)
+	assert.Equal(t, FormatYAML, format, ".yml files should be detected as YAML")
+}
+
+func TestTOMLCodec_Encode(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{"port": 8080, "ratio": 0.5, "ports": [80, 443]}`), FormatJSON))
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, m.SaveToFile(path, FormatTOML))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "port = 8080\n", "whole JSON numbers should be written as integers")
+	assert.Contains(t, string(content), "ratio = 0.5\n")
+
+	reloaded := New()
+	require.NoError(t, reloaded.LoadFile(path))
+	port, err := reloaded.Get("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), port)
+
+	require.NoError(t, m.Set("database.password", nil))
+	err = m.SaveToFile(path, FormatTOML)
+	require.Error(t, err, "null values should be rejected rather than dropped")
+	assert.Contains(t, err.Error(), "database.password")
+}
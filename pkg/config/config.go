@@ -1,15 +1,17 @@
 package config
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
 	"fmt"
-	"gopkg.in/yaml.v3"
 	"io"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
+	"reflect"
 	"sync"
+	"time"
 )
 
 func WithCaseSensitive(sensitive bool) Option {
@@ -20,8 +22,14 @@ func WithCaseSensitive(sensitive bool) Option {
 
 func New(options ...Option) *Manager {
 	m := &Manager{
-		data:          make(map[string]interface{}),
-		caseSensitive: true,
+		data:           make(map[string]interface{}),
+		caseSensitive:  true,
+		envSeparator:   "_",
+		envUpperCase:   true,
+		overwriteCheck: true,
+		watchInterval:  time.Second,
+		watchDebounce:  100 * time.Millisecond,
+		referenceCache: &referenceCache{entries: make(map[string]cachedReference)},
 	}
 
 	for _, option := range options {
@@ -32,92 +40,50 @@ func New(options ...Option) *Manager {
 }
 
 func (m *Manager) LoadFile(filePath string) error {
-	resolvedPath, err := resolvePath(filePath)
-	if err != nil {
-		return &ConfigError{
-			Operation: "resolve path",
-			Err:       err,
-		}
-	}
+	defer m.track()()
 
-	info, err := os.Stat(resolvedPath)
+	loaded, err := m.readFile(filePath, nil)
 	if err != nil {
-		return &ConfigError{
-			Operation: "stat file",
-			Err:       err,
-		}
-	}
-
-	if !info.Mode().IsRegular() {
-		return &ConfigError{
-			Operation: "check file",
-			Err:       fmt.Errorf("file '%s' is not a regular file", filePath),
-		}
-	}
-
-	format, err := detectFileFormat(resolvedPath)
-	if err != nil {
-		return &ConfigError{
-			Operation: "detect file format",
-			Err:       err,
-		}
+		return err
 	}
 
-	file, err := os.Open(resolvedPath)
-	if err != nil {
-		return &ConfigError{
-			Operation: "open file",
-			Err:       err,
-		}
+	if err := m.validateData(loaded.data); err != nil {
+		return err
 	}
 
-	defer func(file *os.File) {
-		_ = file.Close()
-	}(file)
+	m.setFile(loaded)
+	return nil
+}
 
-	return m.Load(file, format)
+// setFile replaces the configuration data with the content of a loaded file and records
+// the file as the one Save writes to.
+func (m *Manager) setFile(loaded *loadedFile) {
+	m.data = loaded.data
+	m.origins = loaded.origins
+	m.yamlDoc = m.yamlDocument(loaded.content, loaded.format)
+	m.includes = loaded.includes
+	m.fileFormat = loaded.format
+	m.filePath = loaded.path
+	m.fileDigest = digest(loaded.content)
 }
 
 func (m *Manager) Load(r io.Reader, format Format) error {
-	content, err := io.ReadAll(r)
-	if err != nil {
-		return &ConfigError{
-			Operation: "read file content",
-			Err:       err,
-		}
-	}
+	defer m.track()()
 
-	switch format {
-	case FormatJSON:
-		err = json.Unmarshal(content, &m.data)
-	case FormatYAML, FormatYML:
-		var data interface{}
-		err = yaml.Unmarshal(content, &data)
-		if err == nil {
-			if mapData, ok := data.(map[string]interface{}); ok {
-				m.data = mapData
-			} else if mapData, ok := data.(map[interface{}]interface{}); ok {
-				transformed := transformMapKeys(mapData)
-				if strMap, ok := transformed.(map[string]interface{}); ok {
-					m.data = strMap
-				} else {
-					err = errors.New("failed to convert YAML data to map[string]interface{}")
-				}
-			} else {
-				err = errors.New("unexpected YAML structure")
-			}
-		}
-	default:
-		err = fmt.Errorf("unsupported file format: %s", format)
+	var content bytes.Buffer
+	data, origins, err := m.decode(io.TeeReader(r, &content), format, Source{Kind: OriginReader})
+	if err != nil {
+		return err
 	}
 
-	if err != nil {
-		return &ConfigError{
-			Operation: "parse",
-			Err:       err,
-		}
+	if err := m.validateData(data); err != nil {
+		return err
 	}
 
+	m.data = data
+	m.origins = origins
+	m.yamlDoc = m.yamlDocument(content.Bytes(), format)
+	m.includes = nil
 	m.fileFormat = format
 	return nil
 }
@@ -130,6 +96,14 @@ func (m *Manager) Bind(target interface{}) error {
 		}
 	}
 
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return &ConfigError{
+			Operation: "bind",
+			Err:       fmt.Errorf("target must be a non-nil pointer, got %T", target),
+		}
+	}
+
 	data, err := m.Get("")
 	if err != nil {
 		return &ConfigError{
@@ -138,58 +112,52 @@ func (m *Manager) Bind(target interface{}) error {
 		}
 	}
 
-	jsonData, err := json.Marshal(data)
+	return m.decodeValue("bind", "", data, value.Elem())
+}
+
+func (m *Manager) Get(key string) (interface{}, error) {
+	value, err := m.getRaw(key)
 	if err != nil {
-		return &ConfigError{
-			Operation: "marshal data",
-			Err:       fmt.Errorf("failed to marshal data: %w", err),
-		}
+		return nil, err
 	}
 
-	err = json.Unmarshal(jsonData, target)
-	if err != nil {
-		return &ConfigError{
-			Operation: "unmarshal data",
-			Err:       fmt.Errorf("failed to unmarshal data into target struct: %w", err),
+	if m.interpolation {
+		if value, err = m.interpolate(key, value, []string{key}); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
-}
-
-func (m *Manager) Get(key string) (interface{}, error) {
-	if key == "" {
-		return m.data, nil
+	if m.references {
+		return m.resolveSecrets(key, value)
 	}
 
-	parentMap, lastKey, err := getNestedMap(m.data, key, m.caseSensitive)
-	if err != nil {
-		return nil, &ConfigError{
-			Operation: "get nested map",
-			Key:       key,
-			Err:       err,
-		}
+	return value, nil
+}
+
+func (m *Manager) getRaw(key string) (interface{}, error) {
+	if value, ok := m.lookupEnv(key); ok {
+		return value, nil
 	}
 
-	if !m.caseSensitive {
-		for key := range parentMap {
-			if strings.EqualFold(key, lastKey) {
-				lastKey = key
-				break
-			}
-		}
+	var value interface{}
+	var err error
+	switch {
+	case len(m.layers) > 0:
+		value, err = m.resolveLayers(key)
+	case key == "":
+		value = m.data
+	default:
+		value, err = findValue(m.data, key, m.caseSensitive)
 	}
 
-	value, exists := parentMap[lastKey]
-	if !exists {
-		return nil, &ConfigError{
-			Operation: "get value",
-			Key:       key,
-			Err:       fmt.Errorf("key '%s' not found", key),
+	// Maps, and keys only set by the environment, include the variables below the key.
+	if _, isMap := value.(map[string]interface{}); isMap || err != nil {
+		if env, ok := envBelow(m.envData(), key, m.caseSensitive); ok {
+			return withNestedEnv(value, env), nil
 		}
 	}
 
-	return value, nil
+	return value, err
 }
 
 func (m *Manager) GetString(key string) (string, error) {
@@ -203,156 +171,76 @@ func (m *Manager) GetString(key string) (string, error) {
 		return v, nil
 	case []byte:
 		return string(v), nil
+	case Secret:
+		return v.Value(), nil
 	default:
 		return fmt.Sprintf("%v", v), nil
 	}
 }
 
 func (m *Manager) GetBool(key string) (bool, error) {
-	value, err := m.Get(key)
-	if err != nil {
-		return false, err
-	}
-
-	switch v := value.(type) {
-	case bool:
-		return v, nil
-	case int:
-		return v != 0, nil
-	case float64:
-		return v != 0, nil
-	case string:
-		lower := strings.ToLower(v)
-		if lower == "true" || lower == "1" || lower == "yes" || lower == "y" || lower == "on" {
-			return true, nil
-		} else if lower == "false" || lower == "0" || lower == "no" || lower == "n" || lower == "off" {
-			return false, nil
-		}
-		return false, &ConfigError{
-			Operation: "convert",
-			Key:       key,
-			Err:       errors.New("cannot convert string to bool"),
-		}
-	default:
-		return false, &ConfigError{
-			Operation: "convert",
-			Key:       key,
-			Err:       fmt.Errorf("cannot convert %T to bool", value),
-		}
-	}
+	return GetAs[bool](m, key)
 }
 
 func (m *Manager) GetInt(key string) (int, error) {
-	value, err := m.Get(key)
-	if err != nil {
-		return 0, err
-	}
-
-	switch v := value.(type) {
-	case int:
-		return v, nil
-	case int64:
-		return int(v), nil
-	case int32:
-		return int(v), nil
-	case float64:
-		return int(v), nil
-	case float32:
-		return int(v), nil
-	case string:
-		var i int
-		_, err := fmt.Sscanf(v, "%d", &i)
-		if err != nil {
-			return 0, &ConfigError{
-				Operation: "convert",
-				Key:       key,
-				Err:       errors.New("cannot convert string to int"),
-			}
-		}
-		return i, nil
-	default:
-		return 0, &ConfigError{
-			Operation: "convert",
-			Key:       key,
-			Err:       fmt.Errorf("cannot convert %T to int", value),
-		}
-	}
+	return GetAs[int](m, key)
 }
 
 func (m *Manager) GetFloat(key string) (float64, error) {
-	value, err := m.Get(key)
-	if err != nil {
-		return 0, err
-	}
-
-	switch v := value.(type) {
-	case float64:
-		return v, nil
-	case float32:
-		return float64(v), nil
-	case int:
-		return float64(v), nil
-	case int32:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case string:
-		var f float64
-		_, err := fmt.Sscanf(v, "%f", &f)
-		if err != nil {
-			return 0, &ConfigError{
-				Operation: "convert",
-				Key:       key,
-				Err:       errors.New("cannot convert string to float"),
-			}
-		}
-		return f, nil
-	default:
-		return 0, &ConfigError{
-			Operation: "convert",
-			Key:       key,
-			Err:       fmt.Errorf("cannot convert %T to float", value),
-		}
-	}
+	return GetAs[float64](m, key)
 }
 
 func (m *Manager) GetStringSlice(key string) ([]string, error) {
-	value, err := m.Get(key)
-	if err != nil {
-		return nil, err
-	}
+	return GetAs[[]string](m, key)
+}
 
-	if strSlice, ok := value.([]string); ok {
-		return strSlice, nil
-	}
+func (m *Manager) GetInt64(key string) (int64, error) {
+	return GetAs[int64](m, key)
+}
 
-	if slice, ok := value.([]interface{}); ok {
-		result := make([]string, len(slice))
-		for i, v := range slice {
-			switch sv := v.(type) {
-			case string:
-				result[i] = sv
-			case []byte:
-				result[i] = string(sv)
-			default:
-				result[i] = fmt.Sprintf("%v", v)
-			}
-		}
-		return result, nil
-	}
+func (m *Manager) GetUint64(key string) (uint64, error) {
+	return GetAs[uint64](m, key)
+}
 
-	if str, ok := value.(string); ok {
-		return []string{str}, nil
-	}
+func (m *Manager) GetDuration(key string) (time.Duration, error) {
+	return GetAs[time.Duration](m, key)
+}
 
-	return nil, &ConfigError{
-		Operation: "convert",
-		Key:       key,
-		Err:       fmt.Errorf("cannot convert %T to []string", value),
-	}
+func (m *Manager) GetTime(key string) (time.Time, error) {
+	return GetAs[time.Time](m, key)
+}
+
+func (m *Manager) GetByteSize(key string) (ByteSize, error) {
+	return GetAs[ByteSize](m, key)
+}
+
+func (m *Manager) GetURL(key string) (*url.URL, error) {
+	return GetAs[*url.URL](m, key)
+}
+
+func (m *Manager) GetIP(key string) (net.IP, error) {
+	return GetAs[net.IP](m, key)
+}
+
+func (m *Manager) GetCIDR(key string) (*net.IPNet, error) {
+	return GetAs[*net.IPNet](m, key)
+}
+
+func (m *Manager) GetIntSlice(key string) ([]int, error) {
+	return GetAs[[]int](m, key)
+}
+
+func (m *Manager) GetStringMap(key string) (map[string]interface{}, error) {
+	return GetAs[map[string]interface{}](m, key)
+}
+
+func (m *Manager) GetStringMapString(key string) (map[string]string, error) {
+	return GetAs[map[string]string](m, key)
 }
 
 func (m *Manager) Set(key string, value interface{}) error {
+	defer m.track()()
+
 	if key == "" {
 		return &ConfigError{
 			Operation: "set",
@@ -361,51 +249,28 @@ func (m *Manager) Set(key string, value interface{}) error {
 		}
 	}
 
-	keys := strings.Split(key, ".")
-	lastIndex := len(keys) - 1
-	lastKey := keys[lastIndex]
-
-	if lastIndex == 0 {
-		m.data[lastKey] = value
-		return nil
-	}
-
-	current := m.data
-	for i := 0; i < lastIndex; i++ {
-		k := keys[i]
-		v, exists := current[k]
-		if !exists {
-			newMap := make(map[string]interface{})
-			current[k] = newMap
-			current = newMap
-			continue
+	segments, err := parseKey(key)
+	if err != nil {
+		return &ConfigError{
+			Operation: "set",
+			Key:       key,
+			Err:       err,
 		}
+	}
 
-		nextMap, ok := v.(map[string]interface{})
-		if !ok {
-			iFaceMap, isIFaceMap := v.(map[interface{}]interface{})
-			if !isIFaceMap {
-				newMap := make(map[string]interface{})
-				current[k] = newMap
-				current = newMap
-				continue
-			}
-
-			nextMap = make(map[string]interface{})
-			for ik, iv := range iFaceMap {
-				strKey, ok := ik.(string)
-				if !ok {
-					strKey = fmt.Sprintf("%v", ik)
-				}
-				nextMap[strKey] = iv
-			}
-			current[k] = nextMap
+	before := touchedLeaves(m.data, segments, "", m.caseSensitive)
+	updated, err := setPath(m.data, segments, value, m.caseSensitive)
+	if err != nil {
+		return &ConfigError{
+			Operation: "set",
+			Key:       key,
+			Err:       err,
 		}
-
-		current = nextMap
 	}
 
-	current[lastKey] = value
+	m.data = updated.(map[string]interface{})
+	m.recordOrigins(before, [][]segment{segments}, m.caseSensitive, &Source{Kind: OriginSet})
+
 	return nil
 }
 
@@ -415,6 +280,8 @@ func (m *Manager) Has(key string) bool {
 }
 
 func (m *Manager) Delete(key string) error {
+	defer m.track()()
+
 	if key == "" {
 		return &ConfigError{
 			Operation: "delete",
@@ -423,7 +290,7 @@ func (m *Manager) Delete(key string) error {
 		}
 	}
 
-	parentMap, lastKey, err := getNestedMap(m.data, key, m.caseSensitive)
+	segments, err := parseKey(key)
 	if err != nil {
 		return &ConfigError{
 			Operation: "delete",
@@ -432,16 +299,17 @@ func (m *Manager) Delete(key string) error {
 		}
 	}
 
-	if !m.caseSensitive {
-		for k := range parentMap {
-			if strings.EqualFold(k, lastKey) {
-				lastKey = k
-				break
-			}
+	before := touchedLeaves(m.data, segments, "", m.caseSensitive)
+	updated, removed, err := deletePath(m.data, segments, m.caseSensitive)
+	if err != nil {
+		return &ConfigError{
+			Operation: "delete",
+			Key:       key,
+			Err:       err,
 		}
 	}
 
-	if _, exists := parentMap[lastKey]; !exists {
+	if removed == 0 {
 		return &ConfigError{
 			Operation: "delete",
 			Key:       key,
@@ -449,7 +317,8 @@ func (m *Manager) Delete(key string) error {
 		}
 	}
 
-	delete(parentMap, lastKey)
+	m.data = updated.(map[string]interface{})
+	m.recordOrigins(before, [][]segment{segments}, m.caseSensitive, nil)
 	return nil
 }
 
@@ -465,6 +334,10 @@ func (m *Manager) Save() error {
 }
 
 func (m *Manager) SaveToFile(path string, format Format) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
 	resolvedPath, err := resolvePath(path)
 	if err != nil {
 		return &ConfigError{
@@ -473,16 +346,34 @@ func (m *Manager) SaveToFile(path string, format Format) error {
 		}
 	}
 
-	var content []byte
-	switch format {
-	case FormatJSON:
-		content, err = json.MarshalIndent(m.data, "", "  ")
-	case FormatYAML, FormatYML:
-		content, err = yaml.Marshal(m.data)
-	default:
-		err = fmt.Errorf("unsupported file format: %s", format)
+	codec, err := m.codec(format)
+	if err != nil {
+		return &ConfigError{
+			Operation: "marshal",
+			Err:       err,
+		}
+	}
+
+	data := m.data
+	if m.includes != nil && samePath(resolvedPath, m.filePath) {
+		// Keep the included files as the source of their keys.
+		data = m.includes.rootData(data)
+	}
+
+	data, err = m.encodableData(data)
+	if err != nil {
+		return &ConfigError{
+			Operation: "encrypt",
+			Err:       err,
+		}
 	}
 
+	var content []byte
+	if m.yamlDoc != nil && m.isYAMLFormat(format) {
+		content, err = encodeYAMLDocument(m.yamlDoc, data)
+	} else {
+		content, err = codec.Encode(data)
+	}
 	if err != nil {
 		return &ConfigError{
 			Operation: "marshal",
@@ -498,20 +389,29 @@ func (m *Manager) SaveToFile(path string, format Format) error {
 		}
 	}
 
-	if err := os.WriteFile(resolvedPath, content, 0644); err != nil {
+	if err := m.writeFile(resolvedPath, content); err != nil {
 		return &ConfigError{
 			Operation: "write file",
 			Err:       err,
 		}
 	}
 
+	if !samePath(resolvedPath, m.filePath) {
+		// The file just written holds the merged data and includes nothing.
+		m.includes = nil
+	}
 	m.filePath = resolvedPath
 	m.fileFormat = format
+	m.fileDigest = digest(content)
 
 	return nil
 }
 
 func (m *Manager) Data() map[string]interface{} {
+	if len(m.layers) > 0 {
+		return m.mergedData()
+	}
+
 	result := make(map[string]interface{})
 
 	for k, v := range m.data {
@@ -532,28 +432,47 @@ func (m *Manager) WithFormat(format Format) *Manager {
 }
 
 func (m *Manager) Clear() {
+	defer m.track()()
+
 	m.data = make(map[string]interface{})
+	m.origins = nil
+	m.yamlDoc = nil
+	m.includes = nil
 }
 
-func (m *Manager) Merge(other *Manager) {
-	m.MergeMap(other.data)
+func (m *Manager) Merge(other *Manager, options ...MergeOption) error {
+	return m.MergeMap(other.data, options...)
 }
 
-func (m *Manager) MergeMap(data map[string]interface{}) {
-	for k, v := range data {
-		if existing, ok := m.data[k]; ok {
-			if existingMap, isMap := existing.(map[string]interface{}); isMap {
-				if newMap, isMap := v.(map[string]interface{}); isMap {
-					for nk, nv := range newMap {
-						existingMap[nk] = nv
-					}
-					continue
-				}
-			}
+func (m *Manager) MergeMap(data map[string]interface{}, options ...MergeOption) error {
+	defer m.track()()
+
+	var previous map[string]interface{}
+	if m.schema != nil {
+		previous = copyValue(m.data).(map[string]interface{})
+	}
+
+	// Merged keys are matched exactly, as mergeMaps does.
+	var touched [][]segment
+	var before []string
+	for key := range flattenLeaves(data) {
+		segments, err := parseKey(key)
+		if err != nil {
+			continue
 		}
+		touched = append(touched, segments)
+		before = append(before, touchedLeaves(m.data, segments, "", true)...)
+	}
+
+	mergeMaps(m.data, data, "", newMergeOptions(options))
 
-		m.data[k] = v
+	if err := m.Validate(); err != nil {
+		m.data = previous
+		return err
 	}
+
+	m.recordOrigins(before, touched, true, &Source{Kind: OriginMerge})
+	return nil
 }
 
 func (m *Manager) ThreadSafe() *ThreadSafeManager {
@@ -599,34 +518,105 @@ func (t *ThreadSafeManager) GetStringSlice(key string) ([]string, error) {
 	return t.manager.GetStringSlice(key)
 }
 
-func (t *ThreadSafeManager) Set(key string, value interface{}) error {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	return t.manager.Set(key, value)
+func (t *ThreadSafeManager) GetInt64(key string) (int64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetInt64(key)
 }
 
-func (t *ThreadSafeManager) Has(key string) bool {
+func (t *ThreadSafeManager) GetUint64(key string) (uint64, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.manager.Has(key)
+	return t.manager.GetUint64(key)
 }
 
-func (t *ThreadSafeManager) Delete(key string) error {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	return t.manager.Delete(key)
+func (t *ThreadSafeManager) GetDuration(key string) (time.Duration, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetDuration(key)
+}
+
+func (t *ThreadSafeManager) GetTime(key string) (time.Time, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetTime(key)
+}
+
+func (t *ThreadSafeManager) GetByteSize(key string) (ByteSize, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetByteSize(key)
+}
+
+func (t *ThreadSafeManager) GetURL(key string) (*url.URL, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetURL(key)
+}
+
+func (t *ThreadSafeManager) GetIP(key string) (net.IP, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetIP(key)
 }
 
-func (t *ThreadSafeManager) Save() error {
+func (t *ThreadSafeManager) GetCIDR(key string) (*net.IPNet, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.manager.Save()
+	return t.manager.GetCIDR(key)
 }
 
-func (t *ThreadSafeManager) SaveToFile(path string, format Format) error {
+func (t *ThreadSafeManager) GetIntSlice(key string) ([]int, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.manager.SaveToFile(path, format)
+	return t.manager.GetIntSlice(key)
+}
+
+func (t *ThreadSafeManager) GetStringMap(key string) (map[string]interface{}, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetStringMap(key)
+}
+
+func (t *ThreadSafeManager) GetStringMapString(key string) (map[string]string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.GetStringMapString(key)
+}
+
+func (t *ThreadSafeManager) Set(key string, value interface{}) (err error) {
+	t.update(func() {
+		err = t.manager.Set(key, value)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) Has(key string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Has(key)
+}
+
+func (t *ThreadSafeManager) Delete(key string) (err error) {
+	t.update(func() {
+		err = t.manager.Delete(key)
+	})
+	return err
+}
+
+// Save and SaveToFile take the write lock, as saving records the file path and digest.
+func (t *ThreadSafeManager) Save() (err error) {
+	t.update(func() {
+		err = t.manager.Save()
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) SaveToFile(path string, format Format) (err error) {
+	t.update(func() {
+		err = t.manager.SaveToFile(path, format)
+	})
+	return err
 }
 
 func (t *ThreadSafeManager) Data() map[string]interface{} {
@@ -636,19 +626,153 @@ func (t *ThreadSafeManager) Data() map[string]interface{} {
 }
 
 func (t *ThreadSafeManager) Clear() {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	t.manager.Clear()
+	t.update(func() {
+		t.manager.Clear()
+	})
 }
 
-func (t *ThreadSafeManager) Merge(other *Manager) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	t.manager.Merge(other)
+func (t *ThreadSafeManager) Merge(other *Manager, options ...MergeOption) (err error) {
+	t.update(func() {
+		err = t.manager.Merge(other, options...)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) MergeMap(data map[string]interface{}, options ...MergeOption) (err error) {
+	t.update(func() {
+		err = t.manager.MergeMap(data, options...)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) AddLayer(name string, data map[string]interface{}) (err error) {
+	t.update(func() {
+		err = t.manager.AddLayer(name, data)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) LoadLayer(name string, r io.Reader, format Format) (err error) {
+	t.update(func() {
+		err = t.manager.LoadLayer(name, r, format)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) LoadFileLayer(name string, filePath string) (err error) {
+	t.update(func() {
+		err = t.manager.LoadFileLayer(name, filePath)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) RemoveLayer(name string) (err error) {
+	t.update(func() {
+		err = t.manager.RemoveLayer(name)
+	})
+	return err
 }
 
-func (t *ThreadSafeManager) MergeMap(data map[string]interface{}) {
+func (t *ThreadSafeManager) Layers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Layers()
+}
+
+func (t *ThreadSafeManager) LayerFor(key string) (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.LayerFor(key)
+}
+
+func (t *ThreadSafeManager) Subscribe(prefix string, handler func(diff Diff)) func() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.manager.MergeMap(data)
+	unsubscribe := t.manager.Subscribe(prefix, handler)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		unsubscribe()
+	}
+}
+
+func (t *ThreadSafeManager) Query(expr string) ([]interface{}, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Query(expr)
+}
+
+func (t *ThreadSafeManager) QueryMatches(expr string) ([]Match, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.QueryMatches(expr)
+}
+
+func (t *ThreadSafeManager) LoadDir(dir string, options ...LoadDirOption) (err error) {
+	t.update(func() {
+		err = t.manager.LoadDir(dir, options...)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) SourceFile(key string) (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.SourceFile(key)
+}
+
+func (t *ThreadSafeManager) Origin(key string) (Source, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Origin(key)
+}
+
+func (t *ThreadSafeManager) Explain(key string) ([]Source, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Explain(key)
+}
+
+// Update and View acquire the file lock before the mutex, so that readers are not blocked
+// while another process holds the file. The mutex is then held while the file is loaded and
+// fn runs, so fn must use the Manager it receives rather than the ThreadSafeManager.
+func (t *ThreadSafeManager) Update(path string, fn func(*Manager) error, options ...LockOption) error {
+	unlock, err := lockPath(path, false, options)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t.update(func() {
+		err = t.manager.updateLocked(path, fn)
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) View(path string, fn func(*Manager) error, options ...LockOption) error {
+	unlock, err := lockPath(path, true, options)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t.update(func() {
+		if err = t.manager.LoadFile(path); err == nil {
+			err = fn(t.manager)
+		}
+	})
+	return err
+}
+
+func (t *ThreadSafeManager) Redacted() map[string]interface{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.Redacted()
+}
+
+func (t *ThreadSafeManager) IsSensitive(key string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.manager.IsSensitive(key)
 }
@@ -3,8 +3,10 @@ package config
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -66,3 +68,232 @@ server:
 	err = m.Load(strings.NewReader("invalid content"), Format("invalid"))
 	assert.Error(t, err, "Load should return an error for invalid format")
 }
+
+func TestManager_LoadTOML(t *testing.T) {
+	tomlContent := `title = "TOMLApp"
+released = 1979-05-27T07:32:00Z
+
+[server]
+host = "localhost"
+port = 7070
+
+[[upstreams]]
+name = "a"
+weight = 3
+
+[[upstreams]]
+name = "b"
+weight = 7`
+
+	m := New()
+	err := m.Load(strings.NewReader(tomlContent), FormatTOML)
+	require.NoError(t, err, "Load should not return an error for valid TOML")
+
+	port, err := m.GetInt("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 7070, port)
+
+	released, err := m.Get("released")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), released.(time.Time).UTC())
+
+	upstreams, err := m.Get("upstreams")
+	require.NoError(t, err)
+	require.IsType(t, []interface{}{}, upstreams, "arrays of tables should decode to []interface{}")
+	assert.Len(t, upstreams, 2)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, m.SaveToFile(path, FormatTOML))
+
+	reloaded := New()
+	require.NoError(t, reloaded.LoadFile(path))
+	assert.Equal(t, m.Data(), reloaded.Data(), "TOML data should round-trip through SaveToFile")
+}
+
+func TestManager_Getters_Conversions(t *testing.T) {
+	m := New()
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"int":      42,
+		"int32":    int32(7),
+		"int64":    int64(8),
+		"float":    3.9,
+		"float32":  float32(2.5),
+		"string":   "42",
+		"spaced":   " 42",
+		"partial":  "12abc",
+		"negative": "-7abc",
+		"decimal":  "2.5kg",
+		"octal":    "010",
+		"hex":      "0x1F",
+		"grouped":  "1_000",
+		"overflow": "99999999999999999999",
+		"word":     "abc",
+		"yes":      "yes",
+		"y":        "Y",
+		"on":       "On",
+		"no":       "NO",
+		"n":        "n",
+		"off":      "off",
+		"one":      "1",
+		"zero":     "0",
+		"maybe":    "maybe",
+		"count":    2,
+		"none":     0.0,
+		"list":     []interface{}{"a", 1, true},
+		"single":   "a",
+	}))
+
+	ints := map[string]int{
+		"int": 42, "int32": 7, "int64": 8, "float": 3, "float32": 2,
+		"string": 42, "spaced": 42, "partial": 12, "negative": -7, "decimal": 2,
+		"octal": 10, "hex": 0, "grouped": 1,
+	}
+	for key, expected := range ints {
+		value, err := m.GetInt(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, expected, value, key)
+	}
+	_, err := m.GetInt("word")
+	assert.Error(t, err)
+	_, err = m.GetInt("overflow")
+	assert.Error(t, err, "GetInt should reject numeric strings out of range")
+	_, err = m.GetInt("yes")
+	assert.Error(t, err)
+
+	floats := map[string]float64{
+		"int": 42, "int32": 7, "int64": 8, "float": 3.9, "float32": 2.5,
+		"string": 42, "spaced": 42, "partial": 12, "decimal": 2.5,
+	}
+	for key, expected := range floats {
+		value, err := m.GetFloat(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, expected, value, key)
+	}
+	_, err = m.GetFloat("word")
+	assert.Error(t, err)
+
+	bools := map[string]bool{
+		"yes": true, "y": true, "on": true, "one": true, "count": true, "int": true,
+		"no": false, "n": false, "off": false, "zero": false, "none": false,
+	}
+	for key, expected := range bools {
+		value, err := m.GetBool(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, expected, value, key)
+	}
+	_, err = m.GetBool("maybe")
+	assert.Error(t, err)
+
+	str, err := m.GetString("float")
+	require.NoError(t, err)
+	assert.Equal(t, "3.9", str)
+
+	list, err := m.GetStringSlice("list")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "1", "true"}, list)
+
+	require.NoError(t, m.Set("mixed", []interface{}{"a", map[string]interface{}{"k": 1}, nil, NewSecret("pw"), 2.5}))
+	mixed, err := m.GetStringSlice("mixed")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "map[k:1]", "<nil>", "pw", "2.5"}, mixed)
+
+	strict := New(WithStrictConversion(true))
+	require.NoError(t, strict.Set("mixed", []interface{}{"a", map[string]interface{}{"k": 1}}))
+	_, err = strict.GetStringSlice("mixed")
+	assert.Error(t, err, "strict conversions should reject elements that are not strings")
+
+	single, err := m.GetStringSlice("single")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, single)
+}
+
+func TestManager_TypedGetters(t *testing.T) {
+	m := New(WithTimeLayouts("02/01/2006"))
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"timeout":  "1m30s",
+		"created":  "2024-03-01T12:00:00Z",
+		"released": "15/06/2023",
+		"cache":    "1.5GB",
+		"buffer":   "512KiB",
+		"endpoint": "https://api.example.com:8443/v1",
+		"ip":       "10.0.0.1",
+		"subnet":   "10.0.0.0/8",
+		"big":      uint64(1 << 63),
+		"negative": -1,
+		"huge":     1e30,
+		"ports":    []interface{}{80, "443"},
+		"labels":   map[string]interface{}{"env": "prod", "replicas": 3},
+	}))
+
+	timeout, err := m.GetDuration("timeout")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	created, err := m.GetTime("created")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), created)
+
+	released, err := m.GetTime("released")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC), released)
+
+	cache, err := m.GetByteSize("cache")
+	require.NoError(t, err)
+	assert.Equal(t, 1500*Megabyte, cache)
+
+	buffer, err := m.GetByteSize("buffer")
+	require.NoError(t, err)
+	assert.Equal(t, 512*Kibibyte, buffer)
+
+	endpoint, err := m.GetURL("endpoint")
+	require.NoError(t, err)
+	assert.Equal(t, "api.example.com:8443", endpoint.Host)
+	assert.Equal(t, "/v1", endpoint.Path)
+
+	ip, err := m.GetIP("ip")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip.String())
+
+	_, err = m.GetIP("endpoint")
+	assert.Error(t, err)
+
+	subnet, err := m.GetCIDR("subnet")
+	require.NoError(t, err)
+	assert.True(t, subnet.Contains(ip))
+
+	big, err := m.GetUint64("big")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1<<63), big)
+
+	_, err = m.GetInt64("big")
+	assert.Error(t, err, "GetInt64 should reject values above math.MaxInt64")
+
+	_, err = m.GetUint64("negative")
+	assert.Error(t, err, "GetUint64 should reject negative values")
+
+	_, err = m.GetInt64("huge")
+	assert.Error(t, err, "GetInt64 should reject floats out of range")
+
+	ports, err := m.GetIntSlice("ports")
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+
+	labels, err := m.GetStringMapString("labels")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "replicas": "3"}, labels)
+
+	raw, err := m.GetStringMap("labels")
+	require.NoError(t, err)
+	raw["env"] = "dev"
+	env, err := m.GetString("labels.env")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", env, "GetStringMap should return a copy")
+
+	_, err = m.GetStringMap("timeout")
+	assert.Error(t, err)
+
+	ts := m.ThreadSafe()
+	timeout, err = ts.GetDuration("timeout")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+}
@@ -0,0 +1,852 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	timeType       = reflect.TypeOf(time.Time{})
+	urlType        = reflect.TypeOf(url.URL{})
+	ipNetType      = reflect.TypeOf(net.IPNet{})
+	regexpPtrType  = reflect.TypeOf(&regexp.Regexp{})
+	secretType     = reflect.TypeOf(Secret{})
+	textUnmarshal  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	defaultLayouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+)
+
+// WithDecodeHook registers a conversion hook applied by Bind before the built-in
+// conversions. Hooks run in registration order and the first hook that converts
+// a value wins.
+func WithDecodeHook(hook DecodeHook) Option {
+	return func(m *Manager) {
+		if hook != nil {
+			m.decodeHooks = append(m.decodeHooks, hook)
+		}
+	}
+}
+
+// WithTimeLayouts adds layouts, in the format of time.Parse, used by GetTime and Bind to
+// parse time values that are not in RFC 3339 format or one of its common variants.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(m *Manager) {
+		m.timeLayouts = append(m.timeLayouts, layouts...)
+	}
+}
+
+// WithStrictConversion makes GetAs, the typed getters and Bind reject conversions that lose
+// information: floats with a fractional part converted to integers, integers that a float
+// cannot represent exactly, numbers other than 0 and 1 converted to booleans, strings that
+// are not entirely a base-10 number, such as "12abc" or " 12 ", and elements of string
+// slices that are not scalars. Values out of range of the target type are rejected in both
+// modes. Conversions are lenient by default, as the getters have always been: floats are
+// truncated when converted to integers, strings are read up to the end of the number they
+// start with, and any element of a string slice is formatted with %v.
+func WithStrictConversion(strict bool) Option {
+	return func(m *Manager) {
+		m.strictConversion = strict
+	}
+}
+
+// decoder converts configuration values into Go values using reflection.
+type decoder struct {
+	caseSensitive bool                            // Whether keys are matched case-sensitively
+	hooks         []DecodeHook                    // Conversion hooks applied before built-in conversions
+	lookupEnv     func(key string) (string, bool) // Environment overlay lookup, may be nil
+	envData       func() map[string]interface{}   // Environment overlay as nested data, nil when disabled
+	env           map[string]interface{}          // Result of envData, loaded on first use
+	sensitive     func(key string) bool           // Reports keys whose values must not appear in errors, may be nil
+	timeLayouts   []string                        // Layouts tried after the default time layouts
+	strict        bool                            // Whether conversions that lose information are rejected
+	violations    []Violation                     // Field errors collected while decoding structs
+}
+
+// decoder returns a decoder configured with the manager's settings.
+func (m *Manager) decoder() *decoder {
+	return &decoder{
+		caseSensitive: m.caseSensitive,
+		hooks:         m.decodeHooks,
+		lookupEnv:     m.lookupEnv,
+		envData:       m.envData,
+		sensitive:     m.sensitiveKey,
+		timeLayouts:   m.timeLayouts,
+		strict:        m.strictConversion,
+	}
+}
+
+// decode converts input into out. Errors for struct fields are collected in d.violations,
+// while errors for the value itself are returned.
+func (d *decoder) decode(key string, input interface{}, out reflect.Value) error {
+	for _, hook := range d.hooks {
+		converted, ok, err := hook(input, out.Type())
+		if err != nil {
+			return err
+		}
+		if ok {
+			input = converted
+			break
+		}
+	}
+
+	if input == nil {
+		return nil
+	}
+
+	if out.Type() == regexpPtrType {
+		return d.decodeRegexp(input, out)
+	}
+
+	if out.Kind() == reflect.Ptr {
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		return d.decode(key, input, out.Elem())
+	}
+
+	inputValue := reflect.ValueOf(input)
+	if inputValue.Type().AssignableTo(out.Type()) {
+		out.Set(copyReflect(inputValue))
+		return nil
+	}
+
+	if secret, ok := input.(Secret); ok {
+		// Decode the plain value, without revealing it in conversion errors.
+		if err := d.decode(key, secret.value, out); err != nil {
+			return conversionError(input, out.Type())
+		}
+		return nil
+	}
+
+	switch out.Type() {
+	case secretType:
+		return d.decodeSecret(input, out)
+	case durationType:
+		return d.decodeDuration(input, out)
+	case timeType:
+		return d.decodeTime(input, out)
+	case urlType:
+		return d.decodeURL(input, out)
+	case ipNetType:
+		return d.decodeIPNet(input, out)
+	}
+
+	if s, ok := input.(string); ok && reflect.PointerTo(out.Type()).Implements(textUnmarshal) {
+		return out.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch out.Kind() {
+	case reflect.Bool:
+		return d.decodeBool(input, out)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.decodeInt(input, out)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return d.decodeUint(input, out)
+	case reflect.Float32, reflect.Float64:
+		return d.decodeFloat(input, out)
+	case reflect.String:
+		return d.decodeString(input, out)
+	case reflect.Slice:
+		return d.decodeSlice(key, input, out)
+	case reflect.Array:
+		return d.decodeArray(key, input, out)
+	case reflect.Map:
+		return d.decodeMap(key, input, out)
+	case reflect.Struct:
+		return d.decodeStruct(key, input, out)
+	case reflect.Interface:
+		if inputValue.Type().Implements(out.Type()) {
+			out.Set(inputValue)
+			return nil
+		}
+	}
+
+	return conversionError(input, out.Type())
+}
+
+func (d *decoder) decodeBool(input interface{}, out reflect.Value) error {
+	if s, ok := input.(string); ok {
+		b, err := parseBool(s)
+		if err != nil {
+			return invalidValueError(input, out.Type(), "%q is not a boolean", s)
+		}
+		out.SetBool(b)
+		return nil
+	}
+
+	if number, ok := toNumber(input); ok {
+		if d.strict && number != 0 && number != 1 {
+			return invalidValueError(input, out.Type(), "%v is neither 0 nor 1", input)
+		}
+		out.SetBool(number != 0)
+		return nil
+	}
+
+	return conversionError(input, out.Type())
+}
+
+func (d *decoder) decodeInt(input interface{}, out reflect.Value) error {
+	var i int64
+
+	switch v := reflect.ValueOf(input); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > 1<<63-1 {
+			return overflowError(input, out.Type())
+		}
+		i = int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if !(f >= math.MinInt64 && f < math.MaxInt64) {
+			return overflowError(input, out.Type())
+		}
+		if d.strict && f != math.Trunc(f) {
+			return invalidValueError(input, out.Type(), "%v has a fractional part", input)
+		}
+		i = int64(f)
+	case reflect.String:
+		parsed, err := d.parseInt(v.String())
+		if err != nil {
+			return parseNumberError(input, out.Type(), err)
+		}
+		i = parsed
+	default:
+		return conversionError(input, out.Type())
+	}
+
+	if out.OverflowInt(i) {
+		return overflowError(input, out.Type())
+	}
+
+	out.SetInt(i)
+	return nil
+}
+
+func (d *decoder) decodeUint(input interface{}, out reflect.Value) error {
+	var u uint64
+
+	switch v := reflect.ValueOf(input); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return overflowError(input, out.Type())
+		}
+		u = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = v.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if !(f >= 0 && f < math.MaxUint64) {
+			return overflowError(input, out.Type())
+		}
+		if d.strict && f != math.Trunc(f) {
+			return invalidValueError(input, out.Type(), "%v has a fractional part", input)
+		}
+		u = uint64(f)
+	case reflect.String:
+		parsed, err := d.parseUint(v.String())
+		if err != nil {
+			return parseNumberError(input, out.Type(), err)
+		}
+		u = parsed
+	default:
+		return conversionError(input, out.Type())
+	}
+
+	if out.OverflowUint(u) {
+		return overflowError(input, out.Type())
+	}
+
+	out.SetUint(u)
+	return nil
+}
+
+func (d *decoder) decodeFloat(input interface{}, out reflect.Value) error {
+	var f float64
+
+	switch v := reflect.ValueOf(input); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+		if d.strict && (f >= math.MaxInt64 || int64(f) != v.Int()) {
+			return invalidValueError(input, out.Type(), "%v cannot be represented exactly", input)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(v.Uint())
+		if d.strict && (f >= math.MaxUint64 || uint64(f) != v.Uint()) {
+			return invalidValueError(input, out.Type(), "%v cannot be represented exactly", input)
+		}
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	case reflect.String:
+		parsed, err := d.parseFloat(v.String())
+		if err != nil {
+			return parseNumberError(input, out.Type(), err)
+		}
+		f = parsed
+	default:
+		return conversionError(input, out.Type())
+	}
+
+	if out.OverflowFloat(f) {
+		return overflowError(input, out.Type())
+	}
+
+	out.SetFloat(f)
+	return nil
+}
+
+// parseInt parses s as a base-10 integer. Lenient conversions fall back to the number s
+// starts with, so that "12abc" is 12 as it has always been for GetInt.
+func (d *decoder) parseInt(s string) (int64, error) {
+	i, err := strconv.ParseInt(d.numberString(s), 10, 64)
+	if err != nil && !d.strict {
+		if _, scanErr := fmt.Sscanf(s, "%d", &i); scanErr == nil {
+			return i, nil
+		}
+	}
+	return i, err
+}
+
+// parseUint parses s as an unsigned integer, like parseInt.
+func (d *decoder) parseUint(s string) (uint64, error) {
+	u, err := strconv.ParseUint(d.numberString(s), 10, 64)
+	if err != nil && !d.strict {
+		if _, scanErr := fmt.Sscanf(s, "%d", &u); scanErr == nil {
+			return u, nil
+		}
+	}
+	return u, err
+}
+
+// parseFloat parses s as a float, like parseInt.
+func (d *decoder) parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(d.numberString(s), 64)
+	if err != nil && !d.strict {
+		if _, scanErr := fmt.Sscanf(s, "%f", &f); scanErr == nil {
+			return f, nil
+		}
+	}
+	return f, err
+}
+
+// numberString returns the string to parse as a number. Surrounding white space is
+// ignored unless conversions are strict.
+func (d *decoder) numberString(s string) string {
+	if d.strict {
+		return s
+	}
+	return strings.TrimSpace(s)
+}
+
+func (d *decoder) decodeString(input interface{}, out reflect.Value) error {
+	switch v := input.(type) {
+	case string:
+		out.SetString(v)
+	case []byte:
+		out.SetString(string(v))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		out.SetString(fmt.Sprintf("%v", v))
+	case fmt.Stringer:
+		out.SetString(v.String())
+	default:
+		return conversionError(input, out.Type())
+	}
+	return nil
+}
+
+func (d *decoder) decodeSlice(key string, input interface{}, out reflect.Value) error {
+	if out.Type().Elem().Kind() == reflect.Uint8 {
+		if s, ok := input.(string); ok {
+			out.SetBytes([]byte(s))
+			return nil
+		}
+	}
+
+	items, ok := toSlice(input)
+	if !ok {
+		// A single value decodes into a slice holding that value.
+		items = []interface{}{input}
+	}
+
+	slice := reflect.MakeSlice(out.Type(), len(items), len(items))
+	for i, item := range items {
+		elem := slice.Index(i)
+		err := d.decode(joinKey(key, strconv.Itoa(i)), item, elem)
+
+		// Lenient conversions format any element of a string slice with %v, as
+		// GetStringSlice always has, including maps and nil elements.
+		if !d.strict && elem.Kind() == reflect.String && (err != nil || item == nil) {
+			elem.SetString(fmt.Sprintf("%v", item))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	out.Set(slice)
+	return nil
+}
+
+func (d *decoder) decodeArray(key string, input interface{}, out reflect.Value) error {
+	items, ok := toSlice(input)
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	if len(items) > out.Len() {
+		return fmt.Errorf("cannot decode %d element(s) into %s", len(items), out.Type())
+	}
+
+	for i, item := range items {
+		if err := d.decode(joinKey(key, strconv.Itoa(i)), item, out.Index(i)); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeMap(key string, input interface{}, out reflect.Value) error {
+	data, ok := input.(map[string]interface{})
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	result := reflect.MakeMapWithSize(out.Type(), len(data))
+	for k, v := range data {
+		mapKey := reflect.New(out.Type().Key()).Elem()
+		if err := d.decode(key, k, mapKey); err != nil {
+			return fmt.Errorf("key '%s': %w", k, err)
+		}
+
+		mapValue := reflect.New(out.Type().Elem()).Elem()
+		if err := d.decode(joinKey(key, k), v, mapValue); err != nil {
+			return fmt.Errorf("key '%s': %w", k, err)
+		}
+
+		result.SetMapIndex(mapKey, mapValue)
+	}
+
+	out.Set(result)
+	return nil
+}
+
+// decodeStruct decodes a map into the exported fields of a struct. Field errors are
+// collected in d.violations so that every failing field is reported.
+func (d *decoder) decodeStruct(key string, input interface{}, out reflect.Value) error {
+	data, ok := input.(map[string]interface{})
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	d.decodeFields(key, data, out)
+	return nil
+}
+
+// decodeFields decodes the fields of the struct out from data.
+func (d *decoder) decodeFields(path string, data map[string]interface{}, out reflect.Value) {
+	outType := out.Type()
+
+	for i := 0; i < outType.NumField(); i++ {
+		field := outType.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		value := out.Field(i)
+		if tag.squash {
+			for value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				d.decodeFields(path, data, value)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		key := joinKey(path, tag.name)
+		input, present := d.lookupField(data, tag.name, tag.explicit)
+
+		if env, ok := d.fieldEnv(key, value.Type()); ok {
+			input, present = env, true
+		} else if env, ok := d.nestedEnv(key); ok {
+			input, present = withNestedEnv(input, env), true
+		}
+
+		if present {
+			if err := d.decode(key, input, value); err != nil {
+				d.fail(key, field.Name, err.Error())
+			}
+		} else if def, ok := field.Tag.Lookup("default"); ok {
+			if err := d.decode(key, defaultInput(def, value.Type()), value); err != nil {
+				d.fail(key, field.Name, fmt.Sprintf("invalid default %q: %v", def, err))
+			}
+		}
+
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			for _, message := range checkRules(value, rules) {
+				d.fail(key, field.Name, message)
+			}
+		}
+
+		if !present {
+			// Apply defaults and validation to nested structs whose key is missing.
+			d.decodeMissing(key, value)
+		}
+	}
+}
+
+// decodeMissing applies defaults and validation rules to a nested struct whose key is
+// missing from the configuration data.
+func (d *decoder) decodeMissing(key string, value reflect.Value) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Struct && value.Type() != timeType && value.Type() != urlType && value.Type() != ipNetType {
+		d.decodeFields(key, nil, value)
+	}
+}
+
+// fail records a field error. Messages about sensitive keys are redacted, as they may
+// contain the value.
+func (d *decoder) fail(key, field, message string) {
+	if d.sensitive != nil && d.sensitive(key) {
+		message = "invalid value " + RedactedValue
+	}
+
+	d.violations = append(d.violations, Violation{
+		Key:     key,
+		Message: fmt.Sprintf("field %s: %s", field, message),
+	})
+}
+
+// fieldEnv returns the environment overlay value of a scalar field.
+func (d *decoder) fieldEnv(key string, fieldType reflect.Type) (interface{}, bool) {
+	if d.lookupEnv == nil {
+		return nil, false
+	}
+
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType.Kind() == reflect.Map || (fieldType.Kind() == reflect.Struct && !reflect.PointerTo(fieldType).Implements(textUnmarshal) &&
+		fieldType != timeType && fieldType != urlType && fieldType != ipNetType) {
+		return nil, false
+	}
+
+	return d.lookupEnv(key)
+}
+
+// nestedEnv returns the environment variables below key as nested data. They apply to maps
+// and structs, which have no variable of their own, including when key is missing from the
+// configuration data.
+func (d *decoder) nestedEnv(key string) (map[string]interface{}, bool) {
+	if d.envData == nil {
+		return nil, false
+	}
+	if d.env == nil {
+		d.env = d.envData()
+	}
+
+	return envBelow(d.env, key, d.caseSensitive)
+}
+
+// envBelow returns the environment data stored below key, or all of it for an empty key.
+func envBelow(env map[string]interface{}, key string, caseSensitive bool) (map[string]interface{}, bool) {
+	if key == "" {
+		return env, len(env) > 0
+	}
+
+	value, err := findValue(env, key, caseSensitive)
+	if err != nil {
+		return nil, false
+	}
+
+	nested, ok := value.(map[string]interface{})
+	return nested, ok && len(nested) > 0
+}
+
+// withNestedEnv returns input with the environment variables below its key merged over it.
+// Input that is neither missing nor a map is returned unchanged.
+func withNestedEnv(input interface{}, env map[string]interface{}) interface{} {
+	switch v := input.(type) {
+	case nil:
+		return copyValue(env)
+	case map[string]interface{}:
+		merged := copyValue(v).(map[string]interface{})
+		deepMerge(merged, env)
+		return merged
+	default:
+		return input
+	}
+}
+
+// lookupField finds the value of a field in data. Keys are matched case-insensitively when
+// the decoder is case-insensitive or the key was derived from the Go field name.
+func (d *decoder) lookupField(data map[string]interface{}, name string, explicit bool) (interface{}, bool) {
+	if value, ok := data[name]; ok {
+		return value, true
+	}
+
+	if d.caseSensitive && explicit {
+		return nil, false
+	}
+
+	for k, v := range data {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func (d *decoder) decodeSecret(input interface{}, out reflect.Value) error {
+	value := reflect.New(reflect.TypeOf("")).Elem()
+	if err := d.decodeString(input, value); err != nil {
+		return err
+	}
+
+	out.Set(reflect.ValueOf(NewSecret(value.String())))
+	return nil
+}
+
+func (d *decoder) decodeDuration(input interface{}, out reflect.Value) error {
+	if s, ok := input.(string); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		out.SetInt(int64(duration))
+		return nil
+	}
+
+	return d.decodeInt(input, out)
+}
+
+func (d *decoder) decodeTime(input interface{}, out reflect.Value) error {
+	s, ok := input.(string)
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	t, err := parseTime(s, defaultLayouts)
+	if err != nil && len(d.timeLayouts) > 0 {
+		t, err = parseTime(s, d.timeLayouts)
+	}
+	if err != nil {
+		return err
+	}
+
+	out.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func (d *decoder) decodeURL(input interface{}, out reflect.Value) error {
+	s, ok := input.(string)
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	out.Set(reflect.ValueOf(*u))
+	return nil
+}
+
+func (d *decoder) decodeIPNet(input interface{}, out reflect.Value) error {
+	s, ok := input.(string)
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+
+	out.Set(reflect.ValueOf(*ipNet))
+	return nil
+}
+
+func (d *decoder) decodeRegexp(input interface{}, out reflect.Value) error {
+	s, ok := input.(string)
+	if !ok {
+		return conversionError(input, out.Type())
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+
+	out.Set(reflect.ValueOf(re))
+	return nil
+}
+
+// fieldTag holds the parsed `cfg` tag of a struct field.
+type fieldTag struct {
+	name     string // Config key of the field
+	explicit bool   // Whether the key was set by a tag rather than derived from the field name
+	skip     bool   // Whether the field is ignored
+	squash   bool   // Whether the fields of an embedded struct are decoded from the same map
+}
+
+// parseFieldTag reads the `cfg` tag of a field, falling back to its `json` tag and then to
+// the field name. Embedded structs without a key are squashed. Other options, such as the
+// omitempty option of json tags, only affect encoding and are ignored.
+func parseFieldTag(field reflect.StructField) fieldTag {
+	tag, ok := field.Tag.Lookup("cfg")
+	if !ok {
+		tag, ok = field.Tag.Lookup("json")
+	}
+
+	parts := strings.Split(tag, ",")
+	result := fieldTag{name: parts[0], explicit: ok && parts[0] != ""}
+
+	if result.name == "-" && len(parts) == 1 {
+		result.skip = true
+		return result
+	}
+
+	for _, option := range parts[1:] {
+		switch strings.TrimSpace(option) {
+		case "squash", "inline":
+			result.squash = true
+		}
+	}
+
+	if result.name == "" {
+		if field.Anonymous {
+			result.squash = true
+		}
+		result.name = field.Name
+	}
+
+	return result
+}
+
+// defaultInput converts a `default` tag into the input decoded into a field. Defaults of
+// slice fields are comma-separated lists.
+func defaultInput(def string, fieldType reflect.Type) interface{} {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType.Kind() != reflect.Slice || fieldType.Elem().Kind() == reflect.Uint8 {
+		return def
+	}
+
+	parts := strings.Split(def, ",")
+	items := make([]interface{}, len(parts))
+	for i, part := range parts {
+		items[i] = strings.TrimSpace(part)
+	}
+	return items
+}
+
+// toSlice converts any slice or array into []interface{}.
+func toSlice(input interface{}) ([]interface{}, bool) {
+	if items, ok := input.([]interface{}); ok {
+		return items, true
+	}
+
+	v := reflect.ValueOf(input)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+// copyReflect returns a copy of maps and slices of configuration data, so that decoded
+// values never alias the manager's data.
+func copyReflect(v reflect.Value) reflect.Value {
+	switch v.Interface().(type) {
+	case map[string]interface{}, []interface{}:
+		return reflect.ValueOf(copyValue(v.Interface()))
+	default:
+		return v
+	}
+}
+
+// parseBool parses the boolean words accepted by GetBool.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "y", "on":
+		return true, nil
+	case "false", "0", "no", "n", "off":
+		return false, nil
+	default:
+		return false, errors.New("cannot convert string to bool")
+	}
+}
+
+// parseTime parses s with the first matching layout.
+func parseTime(s string, layouts []string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
+}
+
+// conversionError reports a value whose type cannot be converted to the target type.
+func conversionError(input interface{}, target reflect.Type) error {
+	return &ConversionError{From: reflect.TypeOf(input), To: target}
+}
+
+// invalidValueError reports a value of a convertible type that cannot be converted to the
+// target type, with the reason formatted from format and args.
+func invalidValueError(input interface{}, target reflect.Type, format string, args ...interface{}) error {
+	return &ConversionError{
+		From:   reflect.TypeOf(input),
+		To:     target,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// overflowError reports a value that does not fit in the target type.
+func overflowError(input interface{}, target reflect.Type) error {
+	return invalidValueError(input, target, "%v is out of range", input)
+}
+
+// parseNumberError reports a string that strconv failed to parse as a number.
+func parseNumberError(input interface{}, target reflect.Type, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return overflowError(input, target)
+	}
+	return invalidValueError(input, target, "%q is not a number", input)
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ignoredFilePatterns match editor swap files, backups and package manager leftovers
+// that LoadDir never loads.
+var ignoredFilePatterns = []string{
+	"*~",
+	"*.swp",
+	"*.swo",
+	"*.bak",
+	"*.orig",
+	"*.tmp",
+	"*.dpkg-old",
+	"*.dpkg-new",
+	"*.dpkg-dist",
+	"*.dpkg-bak",
+	"*.rpmnew",
+	"*.rpmsave",
+	"#*#",
+}
+
+// WithIgnorePatterns makes LoadDir skip files whose name matches any of the given
+// filepath.Match patterns, in addition to hidden and backup files.
+func WithIgnorePatterns(patterns ...string) LoadDirOption {
+	return func(o *loadDirOptions) {
+		o.ignore = append(o.ignore, patterns...)
+	}
+}
+
+// WithRecursive makes LoadDir descend into subdirectories. Files are still loaded in
+// lexical order of their path relative to the directory.
+func WithRecursive(recursive bool) LoadDirOption {
+	return func(o *loadDirOptions) {
+		o.recursive = recursive
+	}
+}
+
+// LoadDir loads every file with a supported format from dir in lexical order and deep-merges
+// them into the configuration, so later files override earlier ones. Hidden files, editor
+// swap files and package manager backups are skipped. The file each key came from is
+// available through SourceFile and Origin.
+func (m *Manager) LoadDir(dir string, options ...LoadDirOption) error {
+	defer m.track()()
+
+	o := &loadDirOptions{}
+	for _, option := range options {
+		option(o)
+	}
+
+	resolvedDir, err := resolvePath(dir)
+	if err != nil {
+		return &ConfigError{
+			Operation: "resolve path",
+			Err:       err,
+		}
+	}
+
+	files, err := m.dirFiles(resolvedDir, o)
+	if err != nil {
+		return &ConfigError{
+			Operation: "read directory",
+			Err:       err,
+		}
+	}
+
+	merged := copyValue(m.data).(map[string]interface{})
+	origins := m.origins
+	for _, file := range files {
+		loaded, err := m.readFile(file, nil)
+		if err != nil {
+			return err
+		}
+
+		deepMerge(merged, loaded.data)
+		origins = overlayOrigins(origins, loaded.origins, merged)
+	}
+
+	if err := m.validateData(merged); err != nil {
+		return err
+	}
+
+	m.data = merged
+	m.origins = origins
+	return nil
+}
+
+// SourceFile returns the file the effective value of a key was loaded from.
+func (m *Manager) SourceFile(key string) (string, error) {
+	source, err := m.Origin(key)
+	if err != nil {
+		return "", err
+	}
+
+	if source.Kind != OriginFile {
+		return "", &ConfigError{
+			Operation: "source file",
+			Key:       key,
+			Err:       fmt.Errorf("key was not loaded from a file but from %s", source),
+		}
+	}
+
+	return source.File, nil
+}
+
+// dirFiles lists the configuration files of dir in lexical order.
+func (m *Manager) dirFiles(dir string, o *loadDirOptions) ([]string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", dir)
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == dir {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if !o.recursive || strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() && entry.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		if ignoredFile(entry.Name(), o.ignore) {
+			return nil
+		}
+
+		if _, err := detectFileFormat(path, m.formatForExtension); err != nil {
+			return nil
+		}
+
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ignoredFile reports whether a file name is hidden, a backup, or matches an extra pattern.
+func ignoredFile(name string, extra []string) bool {
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	for _, patterns := range [][]string{ignoredFilePatterns, extra} {
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
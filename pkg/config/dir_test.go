@@ -0,0 +1,59 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	base := write("10-base.yaml", "server:\n  host: localhost\n  port: 8080\n")
+	override := write("20-override.json", `{"server": {"port": 9090}}`)
+	write("30-ignored.yaml~", "server:\n  port: 1\n")
+	write(".hidden.yaml", "server:\n  port: 2\n")
+	write("40-old.yaml.dpkg-old", "server:\n  port: 3\n")
+	write("50-skip.yaml", "server:\n  port: 4\n")
+	write("README.txt", "not a configuration file")
+	nested := write("sub/60-nested.yaml", "log:\n  level: debug\n")
+
+	m := New()
+	require.NoError(t, m.LoadDir(dir, WithIgnorePatterns("*-skip.yaml")))
+
+	host, err := m.GetString("server.host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", host)
+
+	port, err := m.GetInt("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 9090, port, "later files should override earlier ones")
+	assert.False(t, m.Has("log.level"), "subdirectories should be skipped by default")
+
+	file, err := m.SourceFile("server.host")
+	require.NoError(t, err)
+	assert.Equal(t, base, file)
+
+	file, err = m.SourceFile("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, override, file)
+
+	_, err = m.SourceFile("server.missing")
+	assert.Error(t, err)
+
+	m = New()
+	require.NoError(t, m.LoadDir(dir, WithRecursive(true)))
+	file, err = m.SourceFile("log.level")
+	require.NoError(t, err)
+	assert.Equal(t, nested, file)
+
+	assert.Error(t, New().LoadDir(base), "loading a file as a directory should fail")
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// WithEnvPrefix enables the environment variable overlay. When enabled, Get and the typed
+// getters look up the environment before the configuration data, so that APP_DATABASE_HOST
+// overrides database.host for the prefix "APP". An empty prefix maps keys without a prefix.
+func WithEnvPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.envEnabled = true
+		m.envPrefix = prefix
+	}
+}
+
+// WithEnvSeparator sets the separator placed between the prefix and the key, and used in
+// place of the dots of a key. Defaults to "_". Use "__" to tell nesting apart from keys that
+// contain underscores, so that APP__LOG__FILE_NAME maps to log.file_name.
+func WithEnvSeparator(separator string) Option {
+	return func(m *Manager) {
+		m.envSeparator = separator
+	}
+}
+
+// WithEnvUpperCase controls whether environment variable names are upper-cased. Defaults to true.
+func WithEnvUpperCase(upper bool) Option {
+	return func(m *Manager) {
+		m.envUpperCase = upper
+	}
+}
+
+// WithEnvKeyReplacer sets a replacer applied to keys before they are mapped to environment
+// variable names, e.g. strings.NewReplacer("-", "_") to map log-level to LOG_LEVEL.
+func WithEnvKeyReplacer(replacer *strings.Replacer) Option {
+	return func(m *Manager) {
+		m.envKeyReplacer = replacer
+	}
+}
+
+// EnvVar returns the name of the environment variable that overrides the given key.
+func (m *Manager) EnvVar(key string) string {
+	name := key
+	if m.envKeyReplacer != nil {
+		name = m.envKeyReplacer.Replace(name)
+	}
+
+	name = strings.ReplaceAll(name, ".", m.envSeparator)
+	if m.envPrefix != "" {
+		name = m.envPrefix + m.envSeparator + name
+	}
+
+	if m.envUpperCase {
+		name = strings.ToUpper(name)
+	}
+
+	return name
+}
+
+// EnvData returns the environment variables matching the manager's prefix as a nested map
+// keyed like the configuration data, so it can be passed to MergeMap. A variable that
+// overrides an existing key is stored under that key, so APP_LOG_LEVEL overrides log_level
+// when the data has that key. Other variable names are split on the separator and
+// lower-cased when upper-casing is enabled; use a separator that keys do not contain, such
+// as "__", to nest new keys whose names contain underscores. The key replacer is not reversed.
+func (m *Manager) EnvData() map[string]interface{} {
+	result := make(map[string]interface{})
+
+	prefix := ""
+	if m.envPrefix != "" {
+		prefix = m.envPrefix + m.envSeparator
+		if m.envUpperCase {
+			prefix = strings.ToUpper(prefix)
+		}
+	}
+
+	known := m.envKeys()
+
+	for _, entry := range os.Environ() {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		if keys, ok := known[name]; ok {
+			setEnvValue(result, keys, value)
+			continue
+		}
+
+		name = strings.TrimPrefix(name, prefix)
+		if m.envUpperCase {
+			name = strings.ToLower(name)
+		}
+
+		keys := []string{name}
+		if m.envSeparator != "" {
+			keys = strings.Split(name, m.envSeparator)
+		}
+
+		setEnvValue(result, keys, value)
+	}
+
+	return result
+}
+
+// envKeys returns the segments of the leaf keys of the configuration data, by the name of
+// the environment variable that overrides them.
+func (m *Manager) envKeys() map[string][]string {
+	known := make(map[string][]string)
+
+	var walk func(path []string, value interface{})
+	walk = func(path []string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			for k, v := range nested {
+				walk(append(path[:len(path):len(path)], k), v)
+			}
+			return
+		}
+		known[m.EnvVar(strings.Join(path, "."))] = path
+	}
+
+	for k, v := range m.Data() {
+		walk([]string{k}, v)
+	}
+
+	return known
+}
+
+// envData returns EnvData without the keys set by a source with precedence over the
+// environment overlay, or nil when the overlay is disabled.
+func (m *Manager) envData() map[string]interface{} {
+	if !m.envEnabled {
+		return nil
+	}
+	return m.withoutEnvOverrides(m.EnvData(), "")
+}
+
+// withoutEnvOverrides returns a copy of the environment data stored under prefix without
+// the keys for which envOverridden reports true.
+func (m *Manager) withoutEnvOverrides(data map[string]interface{}, prefix string) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		key := joinKey(prefix, k)
+		if nested, ok := v.(map[string]interface{}); ok {
+			if filtered := m.withoutEnvOverrides(nested, key); len(filtered) > 0 {
+				result[k] = filtered
+			}
+		} else if !m.envOverridden(key) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// lookupEnv returns the value of the environment variable overriding the given key, if any.
+// Variables are ignored for keys set by a source with precedence over the environment overlay.
+func (m *Manager) lookupEnv(key string) (string, bool) {
+	if !m.envEnabled || key == "" {
+		return "", false
+	}
+
+	value, ok := os.LookupEnv(m.EnvVar(key))
+	if !ok || m.envOverridden(key) {
+		return "", false
+	}
+
+	return value, true
+}
+
+// setEnvValue stores value under the nested keys of data. It leaves data unchanged
+// when a key is empty or conflicts with a value stored by another variable.
+func setEnvValue(data map[string]interface{}, keys []string, value string) {
+	for _, key := range keys {
+		if key == "" {
+			return
+		}
+	}
+
+	current := data
+	for _, key := range keys[:len(keys)-1] {
+		next, exists := current[key]
+		if !exists {
+			nextMap := make(map[string]interface{})
+			current[key] = nextMap
+			current = nextMap
+			continue
+		}
+
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = nextMap
+	}
+
+	lastKey := keys[len(keys)-1]
+	if _, isMap := current[lastKey].(map[string]interface{}); isMap {
+		return
+	}
+
+	current[lastKey] = value
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestWithEnvPrefix(t *testing.T) {
+	t.Setenv("APP_DATABASE_HOST", "db.internal")
+	t.Setenv("APP_DATABASE_PORT", "6432")
+	t.Setenv("APP_LOG_LEVEL", "debug")
+
+	m := New(WithEnvPrefix("APP"), WithEnvKeyReplacer(strings.NewReplacer("-", "_")))
+	require.NoError(t, m.Load(strings.NewReader(`{"database": {"host": "localhost", "port": 5432}}`), FormatJSON))
+
+	host, err := m.GetString("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", host, "environment should override file values")
+
+	port, err := m.GetInt("database.port")
+	require.NoError(t, err)
+	assert.Equal(t, 6432, port)
+
+	level, err := m.GetString("log-level")
+	require.NoError(t, err)
+	assert.Equal(t, "debug", level, "key replacer should map log-level to APP_LOG_LEVEL")
+
+	assert.Equal(t, "APP_DATABASE_HOST", m.EnvVar("database.host"))
+	assert.Equal(t, "app.database.host", New(WithEnvPrefix("app"), WithEnvSeparator("."), WithEnvUpperCase(false)).EnvVar("database.host"))
+
+	env := m.EnvData()
+	assert.Equal(t, map[string]interface{}{"host": "db.internal", "port": "6432"}, env["database"])
+
+	plain := New()
+	require.NoError(t, plain.MergeMap(env))
+	host, err = plain.GetString("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", host)
+}
+
+func TestManager_EnvData_UnderscoreKeys(t *testing.T) {
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	t.Setenv("APP_CACHE_MAX_SIZE", "10MB")
+
+	m := New(WithEnvPrefix("APP"))
+	require.NoError(t, m.Load(strings.NewReader(`{"log_level": "info", "cache": {"max_size": "1MB"}}`), FormatJSON))
+
+	env := m.EnvData()
+	assert.Equal(t, "debug", env["log_level"], "variables should map to existing keys containing underscores")
+	assert.Equal(t, map[string]interface{}{"max_size": "10MB"}, env["cache"])
+	assert.NotContains(t, env, "log")
+
+	level, err := m.GetString("log_level")
+	require.NoError(t, err)
+	assert.Equal(t, "debug", level)
+
+	t.Setenv("SVC__FEATURE_FLAGS__NEW_UI", "true")
+	nested := New(WithEnvPrefix("SVC"), WithEnvSeparator("__"))
+	assert.Equal(t, map[string]interface{}{"feature_flags": map[string]interface{}{"new_ui": "true"}}, nested.EnvData())
+}
+
+func TestManager_Bind_NestedEnv(t *testing.T) {
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	t.Setenv("APP_TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("APP_LABELS_TEAM", "platform")
+	t.Setenv("APP_LIMITS_CPU", "4")
+
+	type tls struct {
+		Cert string `cfg:"cert"`
+	}
+	type settings struct {
+		LogLevel string            `cfg:"log_level"`
+		TLS      *tls              `cfg:"tls"`
+		Labels   map[string]string `cfg:"labels"`
+		Limits   map[string]int    `cfg:"limits"`
+	}
+
+	m := New(WithEnvPrefix("APP"))
+	require.NoError(t, m.Load(strings.NewReader(`{"limits": {"cpu": 1, "memory": 512}}`), FormatJSON))
+
+	var s settings
+	require.NoError(t, m.Bind(&s))
+	assert.Equal(t, "debug", s.LogLevel)
+	require.NotNil(t, s.TLS, "a pointer to a struct missing from the data should be set from the environment")
+	assert.Equal(t, "/etc/tls/cert.pem", s.TLS.Cert)
+	assert.Equal(t, map[string]string{"team": "platform"}, s.Labels)
+	assert.Equal(t, map[string]int{"cpu": 4, "memory": 512}, s.Limits)
+}
+
+func TestManager_Get_NestedEnv(t *testing.T) {
+	t.Setenv("APP_DATABASE_HOST", "db.internal")
+	t.Setenv("APP_CACHE_TTL", "60")
+
+	type DBConfig struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	m := New(WithEnvPrefix("APP"))
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"database": map[string]interface{}{"host": "localhost", "port": 5432},
+	}))
+
+	database, err := m.Get("database")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "db.internal", "port": 5432}, database)
+
+	settings, err := m.GetStringMap("database")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", settings["host"])
+
+	db, err := GetAs[DBConfig](m, "database")
+	require.NoError(t, err)
+	assert.Equal(t, DBConfig{Host: "db.internal", Port: 5432}, db)
+
+	cache, err := m.Get("cache")
+	require.NoError(t, err, "keys only set by the environment should be readable as a map")
+	assert.Equal(t, map[string]interface{}{"ttl": "60"}, cache)
+
+	assert.Equal(t, "localhost", m.Data()["database"].(map[string]interface{})["host"], "the stored data should not change")
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// readFile loads a configuration file and the files it includes through IncludeKey.
+// Included files are deep-merged in order, then the content of the including file is
+// merged on top. stack holds the files being loaded and is used to detect include cycles.
+func (m *Manager) readFile(filePath string, stack []string) (*loadedFile, error) {
+	file, format, err := m.openFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	resolvedPath := file.Name()
+	for _, loading := range stack {
+		if loading == resolvedPath {
+			return nil, &ConfigError{
+				Operation: "include",
+				Err:       fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), resolvedPath),
+			}
+		}
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "read file content",
+			Err:       err,
+		}
+	}
+
+	data, origins, err := m.decode(bytes.NewReader(content), format, Source{Kind: OriginFile, File: resolvedPath})
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &loadedFile{
+		data:    data,
+		origins: origins,
+		format:  format,
+		path:    resolvedPath,
+		content: content,
+	}
+
+	rawIncludes, hasIncludes := data[IncludeKey]
+	if !hasIncludes {
+		return loaded, nil
+	}
+	delete(data, IncludeKey)
+	delete(origins, IncludeKey)
+
+	includes, err := includePaths(rawIncludes, filepath.Dir(resolvedPath))
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "include",
+			Key:       IncludeKey,
+			Err:       fmt.Errorf("in '%s': %w", resolvedPath, err),
+		}
+	}
+
+	merged := make(map[string]interface{})
+	mergedOrigins := make(map[string][]Source)
+	stack = append(stack[:len(stack):len(stack)], resolvedPath)
+	for _, include := range includes {
+		included, err := m.readFile(include, stack)
+		if err != nil {
+			return nil, err
+		}
+		deepMerge(merged, included.data)
+		mergedOrigins = overlayOrigins(mergedOrigins, included.origins, merged)
+	}
+
+	loaded.includes = &fileIncludes{
+		directive: rawIncludes,
+		included:  copyValue(merged).(map[string]interface{}),
+		root:      copyValue(data).(map[string]interface{}),
+	}
+
+	deepMerge(merged, data)
+	loaded.data = merged
+	loaded.origins = overlayOrigins(mergedOrigins, origins, merged)
+	return loaded, nil
+}
+
+// rootData returns the data the including file must hold so that loading it again with its
+// includes yields data: the keys set by the file itself, the values that differ from the
+// included files, Tombstones for included keys missing from data, and the include directive.
+func (f *fileIncludes) rootData(data map[string]interface{}) map[string]interface{} {
+	result := withoutIncluded(data, f.included, f.root)
+	result[IncludeKey] = f.directive
+	return result
+}
+
+// withoutIncluded returns the values of data that are set in root or differ from included,
+// and a Tombstone for every key of included missing from data.
+func withoutIncluded(data, included, root map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for k, v := range data {
+		includedValue, isIncluded := included[k]
+		rootValue, inRoot := root[k]
+
+		valueMap, isMap := v.(map[string]interface{})
+		includedMap, includedIsMap := includedValue.(map[string]interface{})
+		if isIncluded && isMap && includedIsMap {
+			rootMap, _ := rootValue.(map[string]interface{})
+			if nested := withoutIncluded(valueMap, includedMap, rootMap); len(nested) > 0 || inRoot {
+				result[k] = nested
+			}
+			continue
+		}
+
+		if inRoot || !isIncluded || !reflect.DeepEqual(v, includedValue) {
+			result[k] = v
+		}
+	}
+
+	for k := range included {
+		if _, exists := data[k]; !exists {
+			result[k] = Tombstone
+		}
+	}
+
+	return result
+}
+
+// includePaths expands the value of an IncludeKey into file paths. Relative paths are
+// resolved against dir, and glob patterns expand to their matches in lexical order.
+// Patterns without matches are ignored, while missing plain paths are reported by the
+// caller when the file is opened.
+func includePaths(value interface{}, dir string) ([]string, error) {
+	var patterns []string
+
+	switch v := value.(type) {
+	case string:
+		patterns = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			pattern, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("include entries must be strings, got %T", item)
+			}
+			patterns = append(patterns, pattern)
+		}
+	default:
+		return nil, fmt.Errorf("include must be a string or a list of strings, got %T", value)
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		pattern = os.ExpandEnv(pattern)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths = append(paths, pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_LoadFileIncludes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	main := write("app.yaml", `
+$include: [db.json, "conf.d/*.yaml"]
+database:
+  host: main.example.com
+`)
+	write("db.json", `{"database": {"host": "db.example.com", "port": 5432}}`)
+	write("conf.d/20-log.yaml", "log:\n  level: warn\n")
+	write("conf.d/10-log.yaml", "log:\n  level: debug\n  format: json\n")
+
+	m := New()
+	require.NoError(t, m.LoadFile(main))
+
+	host, err := m.GetString("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "main.example.com", host, "the including file should override included files")
+
+	port, err := m.GetInt("database.port")
+	require.NoError(t, err)
+	assert.Equal(t, 5432, port, "YAML files should be able to include JSON files")
+
+	level, err := m.GetString("log.level")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", level, "glob matches should be merged in lexical order")
+
+	format, err := m.GetString("log.format")
+	require.NoError(t, err)
+	assert.Equal(t, "json", format)
+	assert.False(t, m.Has(IncludeKey), "the include key should not be part of the configuration")
+
+	cyclic := write("a.yaml", "$include: b.yaml\n")
+	write("b.yaml", "$include: a.yaml\n")
+	err = New().LoadFile(cyclic)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle")
+
+	missing := write("missing.yaml", "$include: nowhere.yaml\n")
+	assert.Error(t, New().LoadFile(missing))
+}
+
+func TestManager_SaveWithIncludes(t *testing.T) {
+	for _, ext := range []string{"yaml", "json"} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			db := filepath.Join(dir, "db.yaml")
+			require.NoError(t, os.WriteFile(db, []byte("db:\n  host: x\n  port: 5432\ncache:\n  ttl: 60\n"), 0644))
+
+			main := filepath.Join(dir, "main."+ext)
+			content := "$include: db.yaml\napp:\n  name: old\ndb:\n  port: 5432\n"
+			if ext == "json" {
+				content = `{"$include": "db.yaml", "app": {"name": "old"}, "db": {"port": 5432}}`
+			}
+			require.NoError(t, os.WriteFile(main, []byte(content), 0644))
+
+			m := New()
+			require.NoError(t, m.LoadFile(main))
+			require.NoError(t, m.Set("app.name", "new"))
+			require.NoError(t, m.Delete("cache.ttl"))
+			require.NoError(t, m.Save())
+
+			saved, err := os.ReadFile(main)
+			require.NoError(t, err)
+			assert.Contains(t, string(saved), IncludeKey, "the include directive should be kept")
+			assert.NotContains(t, string(saved), "host", "included values should not be written to the including file")
+
+			reloaded := New()
+			require.NoError(t, reloaded.LoadFile(main))
+
+			name, err := reloaded.GetString("app.name")
+			require.NoError(t, err)
+			assert.Equal(t, "new", name)
+			assert.False(t, reloaded.Has("cache.ttl"), "deleting an included key should survive a reload")
+
+			for _, key := range []string{"db.host", "db.port"} {
+				assert.True(t, reloaded.Has(key))
+			}
+
+			source, err := reloaded.Origin("db.host")
+			require.NoError(t, err)
+			assert.Equal(t, db, source.File, "the include file should stay the only source of db.host")
+
+			source, err = reloaded.Origin("db.port")
+			require.NoError(t, err)
+			assert.Equal(t, main, source.File, "keys set by the including file should stay in it")
+
+			copyPath := filepath.Join(t.TempDir(), "copy."+ext)
+			require.NoError(t, m.SaveToFile(copyPath, Format(ext)))
+			flattened := New()
+			require.NoError(t, flattened.LoadFile(copyPath))
+			host, err := flattened.GetString("db.host")
+			require.NoError(t, err)
+			assert.Equal(t, "x", host, "saving to another file should write the merged data")
+
+			require.NoError(t, m.Save())
+			require.NoError(t, flattened.LoadFile(copyPath))
+			assert.False(t, flattened.Has(IncludeKey))
+			assert.True(t, flattened.Has("db.host"), "the copy should stay self-contained")
+		})
+	}
+}
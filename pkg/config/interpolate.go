@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WithInterpolation enables expansion of references in string values read through Get and
+// the typed getters. ${server.host} is replaced by the value of another key, ${env:HOME} by
+// an environment variable, and ${env:PORT:-8080} or ${server.port:-8080} fall back to a
+// default when the reference cannot be resolved. $${ produces a literal ${. A string made
+// of a single reference keeps the type of the referenced value.
+func WithInterpolation(enabled bool) Option {
+	return func(m *Manager) {
+		m.interpolation = enabled
+	}
+}
+
+// interpolate expands references in every string within value. chain holds the keys
+// being resolved and is used to detect reference cycles.
+func (m *Manager) interpolate(key string, value interface{}, chain []string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return m.expand(key, v, chain)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			expanded, err := m.interpolate(joinKey(key, k), val, chain)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = expanded
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, val := range v {
+			expanded, err := m.interpolate(joinKey(key, strconv.Itoa(i)), val, chain)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = expanded
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// expand replaces the references in s. A value embedding a Secret becomes a Secret itself.
+func (m *Manager) expand(key, s string, chain []string) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	secret := false
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		end := matchingBrace(s, i+2)
+		if end < 0 {
+			return nil, &ConfigError{
+				Operation: "interpolate",
+				Key:       key,
+				Err:       fmt.Errorf("unterminated reference at offset %d", i),
+			}
+		}
+
+		resolved, err := m.resolveReference(key, s[i+2:end], chain)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 && end == len(s)-1 {
+			// A value made of a single reference keeps the type of the referenced value.
+			return resolved, nil
+		}
+
+		if v, ok := resolved.(Secret); ok {
+			resolved, secret = v.Value(), true
+		}
+
+		b.WriteString(fmt.Sprintf("%v", resolved))
+		i = end + 1
+	}
+
+	if secret {
+		return NewSecret(b.String()), nil
+	}
+	return b.String(), nil
+}
+
+// resolveReference resolves the content of a ${...} reference found in the value of key.
+func (m *Manager) resolveReference(key, ref string, chain []string) (interface{}, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	name = strings.TrimSpace(name)
+
+	fallback := func(err error) (interface{}, error) {
+		if hasDefault {
+			return m.expand(key, def, chain)
+		}
+		return nil, &ConfigError{
+			Operation: "interpolate",
+			Key:       key,
+			Err:       err,
+		}
+	}
+
+	if envName, isEnv := strings.CutPrefix(name, "env:"); isEnv {
+		if value, ok := os.LookupEnv(envName); ok {
+			return value, nil
+		}
+		return fallback(fmt.Errorf("environment variable '%s' is not set", envName))
+	}
+
+	for _, k := range chain {
+		if k == name {
+			return nil, &ConfigError{
+				Operation: "interpolate",
+				Key:       key,
+				Err:       fmt.Errorf("reference cycle: %s -> %s", strings.Join(chain, " -> "), name),
+			}
+		}
+	}
+
+	value, err := m.getRaw(name)
+	if err != nil {
+		return fallback(fmt.Errorf("referenced key '%s' not found", name))
+	}
+
+	return m.interpolate(name, value, append(chain[:len(chain):len(chain)], name))
+}
+
+// matchingBrace returns the index of the '}' closing a reference whose content starts at
+// start, accounting for nested references, or -1 if there is none.
+func matchingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestManager_Interpolation(t *testing.T) {
+	t.Setenv("CFG_TEST_HOME", "/home/app")
+
+	m := New(WithInterpolation(true))
+	require.NoError(t, m.Load(strings.NewReader(`
+server:
+  host: example.com
+  port: 8080
+url: "http://${server.host}:${server.port}/api"
+port: "${server.port}"
+home: "${env:CFG_TEST_HOME}/data"
+fallback: "${env:CFG_TEST_MISSING:-${server.host}}"
+literal: "$${server.host}"
+endpoints: ["${url}/v1"]
+cycle_a: "${cycle_b}"
+cycle_b: "${cycle_a}"
+missing: "${nowhere}"
+`), FormatYAML))
+
+	tests := map[string]interface{}{
+		"url":          "http://example.com:8080/api",
+		"port":         8080,
+		"home":         "/home/app/data",
+		"fallback":     "example.com",
+		"literal":      "${server.host}",
+		"endpoints[0]": "http://example.com:8080/api/v1",
+	}
+	for key, want := range tests {
+		value, err := m.Get(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, want, value, key)
+	}
+
+	port, err := m.GetInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port, "a single reference should keep the referenced type")
+
+	_, err = m.Get("cycle_a")
+	require.Error(t, err)
+	var configErr *ConfigError
+	require.True(t, errors.As(err, &configErr))
+	assert.Contains(t, configErr.Error(), "reference cycle: cycle_a -> cycle_b -> cycle_a")
+
+	_, err = m.Get("missing")
+	assert.Error(t, err)
+
+	plain := New()
+	require.NoError(t, plain.Set("url", "http://${server.host}"))
+	raw, err := plain.GetString("url")
+	require.NoError(t, err)
+	assert.Equal(t, "http://${server.host}", raw, "interpolation should be disabled by default")
+}
+
+func TestManager_Interpolation_UnterminatedReference(t *testing.T) {
+	m := New(WithInterpolation(true))
+	require.NoError(t, m.Set("password", "hunter2${oops"))
+
+	_, err := m.Get("password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password")
+	assert.Contains(t, err.Error(), "offset 7")
+	assert.NotContains(t, err.Error(), "hunter2", "the value should not appear in the error")
+}
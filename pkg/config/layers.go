@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AddLayer adds a named layer of configuration data on top of the existing layers.
+// Layers added later take precedence over layers added earlier, while the manager's own
+// data (RuntimeLayer) takes precedence over all of them. Adding a layer with the name of an
+// existing layer replaces its data in place. Adding an empty layer named EnvLayer places the
+// environment overlay on top of the existing layers, below the layers added afterwards.
+func (m *Manager) AddLayer(name string, data map[string]interface{}) error {
+	return m.addLayer(name, data, leafOrigins(data, Source{Kind: OriginDefault}))
+}
+
+// addLayer adds a named layer with the given origins of its leaf keys.
+func (m *Manager) addLayer(name string, data map[string]interface{}, origins map[string][]Source) error {
+	defer m.track()()
+
+	if name == "" {
+		return &ConfigError{
+			Operation: "add layer",
+			Err:       errors.New("layer name cannot be empty"),
+		}
+	}
+
+	if name == RuntimeLayer {
+		return &ConfigError{
+			Operation: "add layer",
+			Err:       fmt.Errorf("layer name '%s' is reserved", name),
+		}
+	}
+
+	if name == EnvLayer && (!m.envEnabled || len(data) > 0) {
+		return &ConfigError{
+			Operation: "add layer",
+			Err:       errors.New("the env layer only positions the environment overlay enabled by WithEnvPrefix and cannot hold data"),
+		}
+	}
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	for _, l := range m.layers {
+		if l.name == name {
+			l.data = data
+			l.origins = origins
+			return nil
+		}
+	}
+
+	m.layers = append(m.layers, &layer{name: name, data: data, origins: origins})
+	return nil
+}
+
+// LoadLayer parses configuration data from r and adds it as a named layer.
+func (m *Manager) LoadLayer(name string, r io.Reader, format Format) error {
+	data, origins, err := m.decode(r, format, Source{Kind: OriginReader})
+	if err != nil {
+		return err
+	}
+
+	return m.addLayer(name, data, origins)
+}
+
+// LoadFileLayer parses a configuration file, including the files it references through
+// IncludeKey, and adds it as a named layer.
+func (m *Manager) LoadFileLayer(name string, filePath string) error {
+	loaded, err := m.readFile(filePath, nil)
+	if err != nil {
+		return err
+	}
+
+	return m.addLayer(name, loaded.data, loaded.origins)
+}
+
+// RemoveLayer removes a named layer from the layer stack.
+func (m *Manager) RemoveLayer(name string) error {
+	defer m.track()()
+
+	for i, l := range m.layers {
+		if l.name == name {
+			m.layers = append(m.layers[:i], m.layers[i+1:]...)
+			return nil
+		}
+	}
+
+	return &ConfigError{
+		Operation: "remove layer",
+		Err:       fmt.Errorf("layer '%s' not found", name),
+	}
+}
+
+// Layers returns the names of all layers, lowest precedence first.
+func (m *Manager) Layers() []string {
+	names := make([]string, 0, len(m.layers)+2)
+	for _, l := range m.layers {
+		names = append(names, l.name)
+	}
+
+	if m.envEnabled && m.envPosition() < 0 {
+		names = append(names, EnvLayer)
+	}
+
+	return append(names, RuntimeLayer)
+}
+
+// LayerFor returns the name of the layer that supplies the value of a key.
+func (m *Manager) LayerFor(key string) (string, error) {
+	if _, ok := m.lookupEnv(key); ok {
+		return EnvLayer, nil
+	}
+
+	for _, l := range m.stack() {
+		value, err := findValue(l.data, key, m.caseSensitive)
+		if err != nil {
+			continue
+		}
+		if isTombstone(value) {
+			break
+		}
+		return l.name, nil
+	}
+
+	return "", &ConfigError{
+		Operation: "get layer",
+		Key:       key,
+		Err:       fmt.Errorf("key '%s' not found", key),
+	}
+}
+
+// envPosition returns the index of the layer placing the environment overlay in m.layers,
+// or -1 if the overlay lies above every layer.
+func (m *Manager) envPosition() int {
+	for i, l := range m.layers {
+		if l.name == EnvLayer {
+			return i
+		}
+	}
+	return -1
+}
+
+// envOverridden reports whether a source with precedence over the environment overlay sets
+// a key: a value changed with Set, or a layer added after the env layer.
+func (m *Manager) envOverridden(key string) bool {
+	runtime := &layer{name: RuntimeLayer, data: m.data, origins: m.origins}
+	if source, ok := runtime.origin(key, m.caseSensitive); ok && source.Kind == OriginSet {
+		return true
+	}
+
+	position := m.envPosition()
+	if position < 0 {
+		return false
+	}
+
+	for _, l := range m.layers[position+1:] {
+		if _, err := findValue(l.data, key, m.caseSensitive); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stack returns the manager's own data followed by its layers, highest precedence first.
+func (m *Manager) stack() []*layer {
+	stack := make([]*layer, 0, len(m.layers)+1)
+	stack = append(stack, &layer{name: RuntimeLayer, data: m.data, origins: m.origins})
+	for i := len(m.layers) - 1; i >= 0; i-- {
+		stack = append(stack, m.layers[i])
+	}
+	return stack
+}
+
+// mergedData returns a deep copy of all layers merged by precedence.
+func (m *Manager) mergedData() map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, l := range m.layers {
+		deepMerge(result, l.data)
+	}
+	deepMerge(result, m.data)
+	return result
+}
+
+// resolveLayers looks up a key through the layer stack. A scalar value is taken from the
+// highest layer that sets the key, while maps set by several layers are merged by precedence.
+// A Tombstone hides the key from every lower layer.
+func (m *Manager) resolveLayers(key string) (interface{}, error) {
+	if key == "" {
+		return m.mergedData(), nil
+	}
+
+	var found []map[string]interface{}
+	var firstErr error
+
+	for _, l := range m.stack() {
+		value, err := findValue(l.data, key, m.caseSensitive)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		if isTombstone(value) {
+			break
+		}
+
+		valueMap, isMap := value.(map[string]interface{})
+		if !isMap {
+			if len(found) == 0 {
+				return value, nil
+			}
+			break
+		}
+
+		found = append(found, valueMap)
+	}
+
+	switch len(found) {
+	case 0:
+		if firstErr == nil {
+			firstErr = &ConfigError{
+				Operation: "get value",
+				Key:       key,
+				Err:       fmt.Errorf("key '%s' not found", key),
+			}
+		}
+		return nil, firstErr
+	case 1:
+		return found[0], nil
+	}
+
+	merged := make(map[string]interface{})
+	for i := len(found) - 1; i >= 0; i-- {
+		deepMerge(merged, found[i])
+	}
+	return merged, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestManager_Layers(t *testing.T) {
+	m := New()
+	require.NoError(t, m.AddLayer("defaults", map[string]interface{}{
+		"database": map[string]interface{}{"host": "localhost", "port": 5432, "pool": map[string]interface{}{"size": 5}},
+		"debug":    false,
+	}))
+	require.NoError(t, m.LoadLayer("file", strings.NewReader(`database:
+  host: db.example.com
+  pool:
+    timeout: 30`), FormatYAML))
+	require.NoError(t, m.Set("debug", true))
+
+	assert.Equal(t, []string{"defaults", "file", RuntimeLayer}, m.Layers())
+
+	host, err := m.GetString("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", host, "higher layers should take precedence")
+
+	port, err := m.GetInt("database.port")
+	require.NoError(t, err)
+	assert.Equal(t, 5432, port, "keys missing from higher layers should fall through")
+
+	pool, err := m.Get("database.pool")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 5, "timeout": 30}, pool, "maps should merge across layers")
+
+	for key, want := range map[string]string{"database.host": "file", "database.port": "defaults", "debug": RuntimeLayer} {
+		name, err := m.LayerFor(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, name, "LayerFor(%q)", key)
+	}
+
+	_, err = m.LayerFor("missing")
+	assert.Error(t, err)
+
+	assert.Error(t, m.AddLayer(RuntimeLayer, nil), "reserved layer names should be rejected")
+
+	require.NoError(t, m.RemoveLayer("file"))
+	host, err = m.GetString("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", host)
+	assert.Error(t, m.RemoveLayer("file"))
+}
+
+func TestManager_Layers_EnvPrecedence(t *testing.T) {
+	t.Setenv("LAYERS_TEST_HOST", "env.example.com")
+	t.Setenv("LAYERS_TEST_PORT", "6543")
+	t.Setenv("LAYERS_TEST_DEBUG", "true")
+	t.Setenv("LAYERS_TEST_LEVEL", "warn")
+
+	m := New(WithEnvPrefix("LAYERS_TEST"))
+	require.NoError(t, m.AddLayer("defaults", map[string]interface{}{"host": "localhost", "port": 5432, "debug": false, "level": "info"}))
+	require.NoError(t, m.Load(strings.NewReader(`{"host": "file.example.com"}`), FormatJSON))
+	require.NoError(t, m.AddLayer(EnvLayer, nil))
+	require.NoError(t, m.AddLayer("flags", map[string]interface{}{"port": 8080}))
+	require.NoError(t, m.Set("debug", false))
+
+	assert.Equal(t, []string{"defaults", EnvLayer, "flags", RuntimeLayer}, m.Layers())
+
+	for key, want := range map[string]string{"host": EnvLayer, "port": "flags", "debug": RuntimeLayer, "level": EnvLayer} {
+		layer, err := m.LayerFor(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, layer, "layer for %s", key)
+	}
+
+	port, err := m.GetInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port, "a layer added after the env layer should override the environment")
+
+	debug, err := m.GetBool("debug")
+	require.NoError(t, err)
+	assert.False(t, debug, "a value changed with Set should override the environment")
+
+	sources, err := m.Explain("port")
+	require.NoError(t, err)
+	require.Len(t, sources, 3)
+	assert.Equal(t, []string{"defaults", EnvLayer, "flags"}, []string{sources[0].Layer, sources[1].Layer, sources[2].Layer})
+
+	var bound struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port"`
+		Debug bool   `cfg:"debug"`
+	}
+	require.NoError(t, m.Bind(&bound))
+	assert.Equal(t, "env.example.com", bound.Host)
+	assert.Equal(t, 8080, bound.Port)
+	assert.False(t, bound.Debug)
+
+	assert.Error(t, New().AddLayer(EnvLayer, nil), "the env layer requires the environment overlay")
+	assert.Error(t, m.AddLayer(EnvLayer, map[string]interface{}{"host": "x"}), "the env layer cannot hold data")
+}
+
+func TestManager_Layers_CaseInsensitive(t *testing.T) {
+	m := New(WithCaseSensitive(false))
+	require.NoError(t, m.AddLayer("defaults", map[string]interface{}{"Top": "value", "Nested": map[string]interface{}{"Key": 1}}))
+
+	top, err := m.GetString("top")
+	require.NoError(t, err)
+	assert.Equal(t, "value", top)
+
+	key, err := m.GetInt("nested.key")
+	require.NoError(t, err)
+	assert.Equal(t, 1, key)
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLockTimeout is returned by Update and View when the lock of a file cannot be acquired
+// before the lock timeout expires.
+var ErrLockTimeout = errors.New("timed out waiting for file lock")
+
+// lockFileSuffix is appended to the path of a configuration file to name its lock file.
+// Locks are held on a separate file because saving replaces the configuration file. Where
+// flock is available, lock files are left next to the configuration file after the lock is
+// released.
+const lockFileSuffix = ".lock"
+
+// Lock polling intervals, doubled after each failed attempt up to the maximum.
+const (
+	minLockPollInterval = 5 * time.Millisecond
+	maxLockPollInterval = 100 * time.Millisecond
+)
+
+// WithLockTimeout sets how long Update and View wait for a file lock before failing with
+// ErrLockTimeout. A zero timeout, the default, waits indefinitely.
+func WithLockTimeout(timeout time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithStaleLockAge sets the age after which a lock file is considered abandoned by a crashed
+// process and removed. It only applies on platforms without flock, where locks are lock
+// files created exclusively; flock locks are released by the system when their process exits.
+// Defaults to 10 minutes.
+func WithStaleLockAge(age time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.staleAge = age
+	}
+}
+
+// Update loads the file at path under an exclusive lock shared with other processes, calls
+// fn to modify the configuration and saves the file before releasing the lock, so that
+// concurrent updates are never lost. A missing file is created, along with its directory.
+// The lock is held on a ".lock" file next to the configuration file, which is left in place
+// on platforms with flock.
+// The file is not saved when fn returns an error, which is returned as-is.
+func (m *Manager) Update(path string, fn func(*Manager) error, options ...LockOption) error {
+	unlock, err := lockPath(path, false, options)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return m.updateLocked(path, fn)
+}
+
+// updateLocked loads, modifies and saves the file at path while its lock is held by Update.
+func (m *Manager) updateLocked(path string, fn func(*Manager) error) error {
+	if err := m.LoadFile(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		format, err := detectFileFormat(path, m.formatForExtension)
+		if err != nil {
+			return &ConfigError{
+				Operation: "detect file format",
+				Err:       err,
+			}
+		}
+		m.Clear()
+		m.fileFormat = format
+		m.fileDigest = nil
+	}
+
+	if err := fn(m); err != nil {
+		return err
+	}
+
+	return m.SaveToFile(path, m.fileFormat)
+}
+
+// View loads the file at path under a shared lock and calls fn while the lock is held, so
+// that fn never observes a file being updated by another process.
+func (m *Manager) View(path string, fn func(*Manager) error, options ...LockOption) error {
+	unlock, err := lockPath(path, true, options)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.LoadFile(path); err != nil {
+		return err
+	}
+
+	return fn(m)
+}
+
+// lockPath acquires the lock of the configuration file at path, polling until the lock is
+// acquired or the timeout expires. The directory of the file is created for exclusive locks,
+// which Update takes before creating the file. Returns a function releasing the lock.
+func lockPath(path string, shared bool, options []LockOption) (func(), error) {
+	o := &lockOptions{staleAge: 10 * time.Minute}
+	for _, option := range options {
+		option(o)
+	}
+
+	resolvedPath, err := resolvePath(path)
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "resolve path",
+			Err:       err,
+		}
+	}
+
+	if !shared {
+		if err := os.MkdirAll(filepath.Dir(resolvedPath), 0755); err != nil {
+			return nil, &ConfigError{
+				Operation: "create directory",
+				Err:       err,
+			}
+		}
+	}
+
+	var deadline time.Time
+	if o.timeout > 0 {
+		deadline = time.Now().Add(o.timeout)
+	}
+
+	interval := minLockPollInterval
+	for {
+		unlock, err := tryLockFile(resolvedPath+lockFileSuffix, shared, o.staleAge)
+		if err != nil {
+			return nil, &ConfigError{
+				Operation: "lock file",
+				Err:       err,
+			}
+		}
+		if unlock != nil {
+			return unlock, nil
+		}
+
+		if !deadline.IsZero() && time.Now().Add(interval).After(deadline) {
+			return nil, &ConfigError{
+				Operation: "lock file",
+				Err:       ErrLockTimeout,
+			}
+		}
+
+		time.Sleep(interval)
+		interval = min(interval*2, maxLockPollInterval)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !unix
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// tryLockFile tries to create the lock file at path exclusively. Shared locks are exclusive
+// on this platform. A lock file older than staleAge is considered abandoned and removed.
+// Returns a function releasing the lock, or nil if the lock is held by another process.
+func tryLockFile(path string, _ bool, staleAge time.Duration) (func(), error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && staleAge > 0 && time.Since(info.ModTime()) > staleAge {
+			_ = os.Remove(path)
+		}
+		return nil, nil
+	}
+
+	_, _ = fmt.Fprintf(file, "%d\n", os.Getpid())
+	_ = file.Close()
+
+	return func() {
+		_ = os.Remove(path)
+	}, nil
+}
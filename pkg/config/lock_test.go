@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManager_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, New().Update(path, func(m *Manager) error {
+				count, _ := m.GetInt("count")
+				return m.Set("count", count+1)
+			}))
+		}()
+	}
+	wg.Wait()
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	count, err := m.GetInt("count")
+	require.NoError(t, err)
+	assert.Equal(t, 8, count, "no update should be lost")
+
+	failing := errors.New("failing update")
+	err = New().Update(path, func(m *Manager) error {
+		require.NoError(t, m.Set("count", 0))
+		return failing
+	})
+	assert.ErrorIs(t, err, failing)
+
+	require.NoError(t, m.View(path, func(m *Manager) error {
+		count, err := m.GetInt("count")
+		require.NoError(t, err)
+		assert.Equal(t, 8, count, "a failed update should not be saved")
+
+		assert.NoError(t, New().View(path, func(*Manager) error { return nil }, WithLockTimeout(time.Second)),
+			"shared locks should not exclude each other")
+
+		err = New().Update(path, func(*Manager) error { return nil }, WithLockTimeout(20*time.Millisecond))
+		assert.ErrorIs(t, err, ErrLockTimeout)
+		return nil
+	}))
+}
+
+func TestManager_Update_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dir", "app.yaml")
+
+	require.NoError(t, New().Update(path, func(m *Manager) error {
+		return m.Set("name", "app")
+	}), "Update should create the directory of a missing file")
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	name, err := m.GetString("name")
+	require.NoError(t, err)
+	assert.Equal(t, "app", name)
+}
+
+func TestThreadSafeManager_UpdateDoesNotBlockReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+	require.NoError(t, New().Update(path, func(m *Manager) error {
+		return m.Set("count", 1)
+	}))
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	tsm := m.ThreadSafe()
+
+	held, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = New().View(path, func(*Manager) error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	updated := make(chan error, 1)
+	go func() {
+		updated <- tsm.Update(path, func(m *Manager) error {
+			return m.Set("count", 2)
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	read := make(chan int, 1)
+	go func() {
+		count, _ := tsm.GetInt("count")
+		read <- count
+	}()
+	select {
+	case count := <-read:
+		assert.Equal(t, 1, count)
+	case <-time.After(time.Second):
+		t.Fatal("Get should not wait for a file lock held by another process")
+	}
+
+	close(release)
+	require.NoError(t, <-updated)
+	count, err := tsm.GetInt("count")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	require.NoError(t, tsm.Set("count", 3))
+	require.NoError(t, tsm.View(path, func(m *Manager) error {
+		count, err := m.GetInt("count")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count, "fn should see the data of the file it viewed")
+		return nil
+	}))
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithSliceStrategy sets the strategy used to combine slices present in both sides of a merge.
+func WithSliceStrategy(strategy SliceStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy = strategy
+	}
+}
+
+// WithMergeKey merges slices of maps by matching elements on the given identity field,
+// e.g. "name". Elements without a match are appended.
+func WithMergeKey(field string) MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy = SliceMergeKey
+		o.keyField = field
+	}
+}
+
+// ForKey applies merge options only to the slice stored under the given dotted key.
+func ForKey(key string, options ...MergeOption) MergeOption {
+	return func(o *mergeOptions) {
+		if o.keys == nil {
+			o.keys = make(map[string]*mergeOptions)
+		}
+
+		keyOptions := &mergeOptions{}
+		for _, option := range options {
+			option(keyOptions)
+		}
+		o.keys[key] = keyOptions
+	}
+}
+
+// newMergeOptions applies merge options on top of the default replace strategy.
+func newMergeOptions(options []MergeOption) *mergeOptions {
+	o := &mergeOptions{strategy: SliceReplace}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// forKey returns the options that apply to the slice stored under key.
+func (o *mergeOptions) forKey(key string) *mergeOptions {
+	if keyOptions, ok := o.keys[key]; ok {
+		return keyOptions
+	}
+	return o
+}
+
+// deepMerge recursively merges src into dst with the default merge options.
+func deepMerge(dst, src map[string]interface{}) {
+	mergeMaps(dst, src, "", newMergeOptions(nil))
+}
+
+// mergeMaps recursively merges src into dst. Nested maps present in both are merged,
+// slices present in both are combined according to the options, Tombstone values delete
+// the key from dst and any other value from src replaces the value in dst. Values taken
+// from src are copied, so dst never shares maps or slices with src.
+func mergeMaps(dst, src map[string]interface{}, path string, options *mergeOptions) {
+	for k, v := range src {
+		key := joinKey(path, k)
+
+		if isTombstone(v) {
+			delete(dst, k)
+			continue
+		}
+
+		switch sv := v.(type) {
+		case map[string]interface{}:
+			dstMap, ok := dst[k].(map[string]interface{})
+			if !ok {
+				dstMap = make(map[string]interface{})
+				dst[k] = dstMap
+			}
+			mergeMaps(dstMap, sv, key, options)
+		case []interface{}:
+			if dstSlice, ok := dst[k].([]interface{}); ok {
+				dst[k] = mergeSlices(dstSlice, sv, key, options)
+				continue
+			}
+			dst[k] = copyValue(sv)
+		default:
+			dst[k] = v
+		}
+	}
+}
+
+// mergeSlices combines two slices according to the strategy that applies to key.
+func mergeSlices(dst, src []interface{}, key string, options *mergeOptions) []interface{} {
+	keyOptions := options.forKey(key)
+
+	switch keyOptions.strategy {
+	case SliceAppend:
+		return append(dst, copyValue(src).([]interface{})...)
+	case SliceUnion:
+		for _, v := range src {
+			if !containsValue(dst, v) {
+				dst = append(dst, copyValue(v))
+			}
+		}
+		return dst
+	case SliceMergeIndex:
+		for i, v := range src {
+			if i < len(dst) {
+				dst[i] = mergeElement(dst[i], v, joinKey(key, strconv.Itoa(i)), options)
+				continue
+			}
+			dst = append(dst, copyValue(v))
+		}
+		return dst
+	case SliceMergeKey:
+		for _, v := range src {
+			index := indexByField(dst, v, keyOptions.keyField)
+			if index < 0 {
+				dst = append(dst, copyValue(v))
+				continue
+			}
+			dst[index] = mergeElement(dst[index], v, joinKey(key, strconv.Itoa(index)), options)
+		}
+		return dst
+	default:
+		return copyValue(src).([]interface{})
+	}
+}
+
+// mergeElement merges two slice elements, merging maps and replacing any other value.
+func mergeElement(dst, src interface{}, key string, options *mergeOptions) interface{} {
+	dstMap, dstIsMap := dst.(map[string]interface{})
+	srcMap, srcIsMap := src.(map[string]interface{})
+	if dstIsMap && srcIsMap {
+		mergeMaps(dstMap, srcMap, key, options)
+		return dstMap
+	}
+	return copyValue(src)
+}
+
+// indexByField returns the index of the map element in slice whose field equals the
+// field of v, or -1 if v is not a map carrying the field or no element matches.
+func indexByField(slice []interface{}, v interface{}, field string) int {
+	vMap, ok := v.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+
+	id, ok := vMap[field]
+	if !ok {
+		return -1
+	}
+
+	for i, element := range slice {
+		if elementMap, ok := element.(map[string]interface{}); ok {
+			if elementID, ok := elementMap[field]; ok && reflect.DeepEqual(elementID, id) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// containsValue reports whether slice contains a value deeply equal to v.
+func containsValue(slice []interface{}, v interface{}) bool {
+	for _, element := range slice {
+		if reflect.DeepEqual(element, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTombstone reports whether v is the Tombstone value.
+func isTombstone(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && s == Tombstone
+}
+
+// joinKey appends a raw key segment to a dotted key path, escaping it where needed.
+func joinKey(path, key string) string {
+	if path == "" {
+		return escapeKey(key)
+	}
+	return path + "." + escapeKey(key)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestManager_MergeMap(t *testing.T) {
+	base := func() *Manager {
+		m := New()
+		m.MergeMap(map[string]interface{}{
+			"server": map[string]interface{}{
+				"tls":  map[string]interface{}{"enabled": true, "cert": "/etc/cert.pem"},
+				"tags": []interface{}{"a", "b"},
+			},
+			"upstreams": []interface{}{
+				map[string]interface{}{"name": "api", "weight": 1},
+				map[string]interface{}{"name": "web", "weight": 2},
+			},
+			"legacy": "value",
+		})
+		return m
+	}
+
+	overlay := map[string]interface{}{
+		"server": map[string]interface{}{
+			"tls":  map[string]interface{}{"cert": "/run/cert.pem"},
+			"tags": []interface{}{"b", "c"},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"name": "web", "weight": 5},
+			map[string]interface{}{"name": "admin", "weight": 1},
+		},
+		"legacy": Tombstone,
+	}
+
+	m := base()
+	m.MergeMap(overlay)
+	enabled, err := m.GetBool("server.tls.enabled")
+	require.NoError(t, err)
+	assert.True(t, enabled, "deeply nested keys should survive a merge")
+	cert, _ := m.GetString("server.tls.cert")
+	assert.Equal(t, "/run/cert.pem", cert)
+	tags, _ := m.GetStringSlice("server.tags")
+	assert.Equal(t, []string{"b", "c"}, tags, "slices should be replaced by default")
+	assert.False(t, m.Has("legacy"), "tombstones should delete keys")
+
+	m = base()
+	m.MergeMap(overlay, WithSliceStrategy(SliceAppend))
+	tags, _ = m.GetStringSlice("server.tags")
+	assert.Equal(t, []string{"a", "b", "b", "c"}, tags)
+
+	m = base()
+	m.MergeMap(overlay, ForKey("server.tags", WithSliceStrategy(SliceUnion)), ForKey("upstreams", WithMergeKey("name")))
+	tags, _ = m.GetStringSlice("server.tags")
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+	upstreams, _ := m.Get("upstreams")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "api", "weight": 1},
+		map[string]interface{}{"name": "web", "weight": 5},
+		map[string]interface{}{"name": "admin", "weight": 1},
+	}, upstreams)
+
+	m = base()
+	m.MergeMap(overlay, WithSliceStrategy(SliceMergeIndex))
+	upstreams, _ = m.Get("upstreams")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "web", "weight": 5},
+		map[string]interface{}{"name": "admin", "weight": 1},
+	}, upstreams)
+
+	layered := New()
+	require.NoError(t, layered.AddLayer("base", map[string]interface{}{"legacy": "value"}))
+	require.NoError(t, layered.AddLayer("overlay", map[string]interface{}{"legacy": Tombstone}))
+	assert.False(t, layered.Has("legacy"), "tombstones should hide keys set by lower layers")
+	assert.NotContains(t, layered.Data(), "legacy")
+}
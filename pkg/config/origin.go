@@ -0,0 +1,471 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// positionCodec is implemented by codecs that can report the location of each key of a
+// document. Keys are dotted keys as produced by joinKey.
+type positionCodec interface {
+	positions(content []byte) (map[string]position, error)
+}
+
+// Origin returns the source that set the effective value of a leaf key: a file with the
+// line and column of the key, a reader, an environment variable, a Set or Merge call, or
+// a layer added with AddLayer. Slice elements report the origin of their slice. The value
+// of a sensitive key is replaced by RedactedValue, as in Redacted.
+func (m *Manager) Origin(key string) (Source, error) {
+	if value, ok := m.lookupEnv(key); ok {
+		return m.redactSource(m.envSource(key, value), key), nil
+	}
+
+	for _, l := range m.stack() {
+		value, err := findValue(l.data, key, m.caseSensitive)
+		if err != nil {
+			continue
+		}
+		if isTombstone(value) {
+			break
+		}
+
+		if source, ok := l.origin(key, m.caseSensitive); ok {
+			return m.redactSource(source, key), nil
+		}
+		break
+	}
+
+	return Source{}, &ConfigError{
+		Operation: "get origin",
+		Key:       key,
+		Err:       fmt.Errorf("no origin recorded for key '%s'", key),
+	}
+}
+
+// Explain returns the history of sources that set a leaf key, lowest precedence first:
+// every source of each layer in the order it set the key, for instance each file of a
+// directory loaded with LoadDir or each MergeMap call. The last source is the one reported
+// by Origin, unless a Tombstone in a higher layer deletes the key, in which case the
+// Tombstone is listed with its own source. An environment variable is listed below the
+// sources that take precedence over it. Values are redacted like those of Origin.
+func (m *Manager) Explain(key string) ([]Source, error) {
+	var sources []Source
+	envAt := -1
+
+	position := m.envPosition()
+	stack := m.stack()
+	for i := len(stack) - 1; i >= 0; i-- {
+		history, ok := stack[i].history(key, m.caseSensitive)
+		if !ok {
+			continue
+		}
+
+		// Sources with precedence over the environment overlay are listed after it. In the
+		// runtime layer, only a Set call that last changed the key takes precedence.
+		latest := history[len(history)-1]
+		above := (i == 0 && latest.Kind == OriginSet) || (i > 0 && position >= 0 && len(stack)-1-i > position)
+		if above && envAt < 0 {
+			envAt = len(sources)
+			if i == 0 {
+				envAt += len(history) - 1
+			}
+		}
+		sources = append(sources, history...)
+	}
+
+	if value, ok := os.LookupEnv(m.EnvVar(key)); ok && m.envEnabled {
+		if envAt < 0 {
+			envAt = len(sources)
+		}
+		sources = append(sources[:envAt], append([]Source{m.envSource(key, value)}, sources[envAt:]...)...)
+	}
+
+	if len(sources) == 0 {
+		return nil, &ConfigError{
+			Operation: "explain",
+			Key:       key,
+			Err:       fmt.Errorf("no origin recorded for key '%s'", key),
+		}
+	}
+
+	for i, source := range sources {
+		sources[i] = m.redactSource(source, key)
+	}
+
+	return sources, nil
+}
+
+// redactSource returns source with its value redacted for key.
+func (m *Manager) redactSource(source Source, key string) Source {
+	source.Value = m.redact(source.Value, key)
+	return source
+}
+
+// String returns the layer and location of the source, e.g. "runtime: /etc/app.yaml:3:5"
+// or "env: APP_PORT".
+func (s Source) String() string {
+	var location string
+
+	switch s.Kind {
+	case OriginFile, OriginReader:
+		location = s.File
+		if s.Kind == OriginReader {
+			location = string(OriginReader)
+		}
+		if s.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", location, s.Line, s.Column)
+		}
+	case OriginEnv:
+		location = s.EnvVar
+	default:
+		location = string(s.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s", s.Layer, location)
+}
+
+// envSource returns the source of a value supplied by the environment variable overlay.
+func (m *Manager) envSource(key string, value string) Source {
+	return Source{
+		Kind:   OriginEnv,
+		Layer:  EnvLayer,
+		EnvVar: m.EnvVar(key),
+		Value:  value,
+	}
+}
+
+// origin returns the source of the first value matched by key in the layer. Values without
+// a recorded origin of their own, such as slice elements, report the origin of their nearest
+// recorded ancestor.
+func (l *layer) origin(key string, caseSensitive bool) (Source, bool) {
+	history, ok := l.history(key, caseSensitive)
+	if !ok {
+		return Source{}, false
+	}
+	return history[len(history)-1], true
+}
+
+// history returns the sources that set the first value matched by key in the layer, oldest
+// first. Each source carries the value it set, and the last one the current value.
+func (l *layer) history(key string, caseSensitive bool) ([]Source, bool) {
+	segments, err := parseKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	var concrete string
+	var value interface{}
+	found := false
+	_ = lookupPath(l.data, segments, "", caseSensitive, func(k string, v interface{}) {
+		if !found {
+			concrete, value, found = k, v, true
+		}
+	})
+	if !found {
+		return nil, false
+	}
+
+	for k := concrete; ; k = parentKey(k) {
+		if recorded := l.origins[k]; len(recorded) > 0 {
+			// Sources recorded for an ancestor set the value below it.
+			var rest []segment
+			if k != concrete {
+				rest, _ = parseKey(strings.TrimPrefix(strings.TrimPrefix(concrete, k), "."))
+			}
+
+			history := make([]Source, len(recorded))
+			for i, source := range recorded {
+				source.Layer = l.name
+				if rest != nil {
+					source.Value = nil
+					_ = lookupPath(recorded[i].Value, rest, "", true, func(_ string, v interface{}) {
+						source.Value = v
+					})
+				}
+				history[i] = source
+			}
+			history[len(history)-1].Value = value
+			return history, true
+		}
+		if k == "" {
+			return nil, false
+		}
+	}
+}
+
+// parentKey returns the dotted key without its last segment, or "" for a top-level key.
+func parentKey(key string) string {
+	segments, err := parseKey(key)
+	if err != nil || len(segments) <= 1 {
+		return ""
+	}
+
+	parent := ""
+	for _, seg := range segments[:len(segments)-1] {
+		parent = joinKey(parent, seg.key)
+	}
+	return parent
+}
+
+// leafOrigins returns an origin map assigning source, with the value it sets, to every
+// leaf key of data.
+func leafOrigins(data map[string]interface{}, source Source) map[string][]Source {
+	origins := make(map[string][]Source)
+	for key, value := range flattenLeaves(data) {
+		source.Value = value
+		origins[key] = []Source{source}
+	}
+	return origins
+}
+
+// overlayOrigins returns the origins of dst with the history of src appended, keeping only
+// the leaf keys present in data. It is used after src has been merged into data.
+func overlayOrigins(dst, src map[string][]Source, data map[string]interface{}) map[string][]Source {
+	origins := make(map[string][]Source)
+	for key := range flattenLeaves(data) {
+		history := dst[key]
+		history = append(history[:len(history):len(history)], src[key]...)
+		if len(history) > 0 {
+			origins[key] = history
+		}
+	}
+	return origins
+}
+
+// recordOrigins updates the origins of the runtime layer after the values matched by
+// segments changed. before holds the leaves touched by segments before the change: those
+// that no longer exist lose their history. Unless source is nil, it is appended to the
+// history of every leaf touched by segments after the change.
+func (m *Manager) recordOrigins(before []string, segments [][]segment, caseSensitive bool, source *Source) {
+	after := make(map[string]bool)
+	for _, s := range segments {
+		for _, leaf := range touchedLeaves(m.data, s, "", caseSensitive) {
+			after[leaf] = true
+		}
+	}
+
+	if m.origins == nil {
+		m.origins = make(map[string][]Source)
+	}
+	for _, leaf := range before {
+		if !after[leaf] {
+			delete(m.origins, leaf)
+		}
+	}
+
+	if source == nil {
+		return
+	}
+	for leaf := range after {
+		recorded := *source
+		if value, err := findValue(m.data, leaf, true); err == nil {
+			recorded.Value = copyValue(value)
+		}
+		history := m.origins[leaf]
+		m.origins[leaf] = append(history[:len(history):len(history)], recorded)
+	}
+}
+
+// touchedLeaves returns the leaf keys of value, found at path, that a change of the values
+// matched by segments affects: the leaves below the matched values, or the slice or scalar
+// on the way that holds them. Only the touched part of value is walked.
+func touchedLeaves(value interface{}, segments []segment, path string, caseSensitive bool) []string {
+	if len(segments) == 0 {
+		return leafKeys(value, path)
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok || (len(m) == 0 && path != "") {
+		if path == "" {
+			return nil
+		}
+		return []string{path}
+	}
+
+	keys := []string{segments[0].mapKey(m, caseSensitive)}
+	if segments[0].wildcard {
+		keys = sortedKeys(m)
+	}
+
+	var leaves []string
+	for _, k := range keys {
+		if child, exists := m[k]; exists {
+			leaves = append(leaves, touchedLeaves(child, segments[1:], joinKey(path, k), caseSensitive)...)
+		}
+	}
+	return leaves
+}
+
+// leafKeys returns the dotted keys of the leaves of value, found at key, as flattenLeaves
+// reports them.
+func leafKeys(value interface{}, key string) []string {
+	nested, ok := value.(map[string]interface{})
+	if !ok || len(nested) == 0 {
+		return []string{key}
+	}
+
+	var keys []string
+	for k, v := range nested {
+		keys = append(keys, leafKeys(v, joinKey(key, k))...)
+	}
+	return keys
+}
+
+// decodeOrigins returns the origins of the leaf keys of data decoded from content, adding
+// the line and column of each key when the codec can report them.
+func decodeOrigins(codec Codec, content []byte, data map[string]interface{}, source Source) map[string][]Source {
+	origins := leafOrigins(data, source)
+
+	pc, ok := codec.(positionCodec)
+	if !ok {
+		return origins
+	}
+
+	positions, err := pc.positions(content)
+	if err != nil {
+		return origins
+	}
+
+	for key, history := range origins {
+		if p, ok := positions[key]; ok {
+			history[0].Line, history[0].Column = p.line, p.column
+		}
+	}
+
+	return origins
+}
+
+// jsonPositions returns the position of every object key of a JSON document outside arrays.
+func jsonPositions(content []byte) (map[string]position, error) {
+	positions := make(map[string]position)
+	lines := lineStarts(content)
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('['):
+			for depth := 1; depth > 0; {
+				token, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				switch token {
+				case json.Delim('['), json.Delim('{'):
+					depth++
+				case json.Delim(']'), json.Delim('}'):
+					depth--
+				}
+			}
+			return nil
+		case json.Delim('{'):
+		default:
+			return nil
+		}
+
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			key := joinKey(path, fmt.Sprint(token))
+			positions[key] = offsetPosition(lines, jsonStringStart(content, int(dec.InputOffset())))
+
+			if err := walk(key); err != nil {
+				return err
+			}
+		}
+
+		_, err = dec.Token()
+		return err
+	}
+
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+// jsonStringStart returns the offset of the opening quote of the JSON string ending at end.
+func jsonStringStart(content []byte, end int) int {
+	for i := end - 2; i >= 0; i-- {
+		if content[i] != '"' {
+			continue
+		}
+
+		backslashes := 0
+		for j := i - 1; j >= 0 && content[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// lineStarts returns the offsets at which each line of content starts.
+func lineStarts(content []byte) []int {
+	starts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// offsetPosition converts a byte offset into a line and column using the line starts.
+func offsetPosition(starts []int, offset int) position {
+	line := sort.Search(len(starts), func(i int) bool {
+		return starts[i] > offset
+	})
+	return position{line: line, column: offset - starts[line-1] + 1}
+}
+
+// yamlPositions returns the position of every mapping key of a YAML document outside sequences.
+func yamlPositions(content []byte) (map[string]position, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]position)
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keyNode, valueNode := node.Content[i], node.Content[i+1]
+				if keyNode.Tag == "!!merge" {
+					continue
+				}
+
+				key := joinKey(path, keyNode.Value)
+				positions[key] = position{line: keyNode.Line, column: keyNode.Column}
+				walk(valueNode, key)
+			}
+		}
+	}
+	walk(&root, "")
+
+	return positions, nil
+}
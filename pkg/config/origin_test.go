@@ -0,0 +1,190 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManager_Origin(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("server:\n  host: localhost\n  port: 8080\nservers:\n  - a\n  - b\n"), 0644))
+
+	m := New(WithEnvPrefix("ORIGIN_TEST"))
+	require.NoError(t, m.AddLayer("defaults", map[string]interface{}{
+		"server": map[string]interface{}{"port": 80, "timeout": 30},
+	}))
+	require.NoError(t, m.LoadFile(yamlPath))
+
+	source, err := m.Origin("server.host")
+	require.NoError(t, err)
+	assert.Equal(t, OriginFile, source.Kind)
+	assert.Equal(t, RuntimeLayer, source.Layer)
+	assert.Equal(t, yamlPath, source.File)
+	assert.Equal(t, 2, source.Line)
+	assert.Equal(t, 3, source.Column)
+	assert.Equal(t, "localhost", source.Value)
+
+	source, err = m.Origin("servers[1]")
+	require.NoError(t, err)
+	assert.Equal(t, 4, source.Line, "slice elements should report the origin of their slice")
+	assert.Equal(t, "b", source.Value)
+
+	source, err = m.Origin("server.timeout")
+	require.NoError(t, err)
+	assert.Equal(t, OriginDefault, source.Kind)
+	assert.Equal(t, "defaults", source.Layer)
+
+	require.NoError(t, m.Set("server.host", "example.com"))
+	source, err = m.Origin("server.host")
+	require.NoError(t, err)
+	assert.Equal(t, OriginSet, source.Kind)
+
+	require.NoError(t, m.Load(strings.NewReader(`{
+  "server": {"host": "json.example.com", "port": 9090}
+}`), FormatJSON))
+	source, err = m.Origin("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, OriginReader, source.Kind)
+	assert.Equal(t, 2, source.Line)
+	assert.Equal(t, 42, source.Column)
+
+	require.NoError(t, m.MergeMap(map[string]interface{}{"server": map[string]interface{}{"port": 7070}}))
+	source, err = m.Origin("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, OriginMerge, source.Kind)
+
+	t.Setenv("ORIGIN_TEST_SERVER_PORT", "6060")
+	source, err = m.Origin("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, OriginEnv, source.Kind)
+	assert.Equal(t, "ORIGIN_TEST_SERVER_PORT", source.EnvVar)
+	assert.Equal(t, "env: ORIGIN_TEST_SERVER_PORT", source.String())
+
+	sources, err := m.Explain("server.port")
+	require.NoError(t, err)
+	require.Len(t, sources, 4)
+	assert.Equal(t, []OriginKind{OriginDefault, OriginReader, OriginMerge, OriginEnv},
+		[]OriginKind{sources[0].Kind, sources[1].Kind, sources[2].Kind, sources[3].Kind})
+	assert.Equal(t, 80, sources[0].Value)
+	assert.Equal(t, float64(9090), sources[1].Value)
+	assert.Equal(t, 7070, sources[2].Value)
+
+	require.NoError(t, m.Delete("server.host"))
+	_, err = m.Origin("server.host")
+	assert.Error(t, err)
+	_, err = m.Explain("server.missing")
+	assert.Error(t, err)
+}
+
+func TestManager_OriginIncludes(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "app.yaml")
+	db := filepath.Join(dir, "db.json")
+	require.NoError(t, os.WriteFile(main, []byte("$include: db.json\nname: app\n"), 0644))
+	require.NoError(t, os.WriteFile(db, []byte(`{"database": {"host": "db"}}`), 0644))
+
+	m := New()
+	require.NoError(t, m.LoadFile(main))
+
+	file, err := m.SourceFile("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, db, file)
+
+	source, err := m.Origin("name")
+	require.NoError(t, err)
+	assert.Equal(t, "runtime: "+main+":2:1", source.String())
+}
+
+func TestManager_Origin_DottedKeys(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{
+  "example.com": {"port": 443}
+}`), FormatJSON))
+
+	source, err := m.Origin(`example\.com.port`)
+	require.NoError(t, err)
+	assert.Equal(t, OriginReader, source.Kind)
+	assert.Equal(t, 2, source.Line)
+
+	require.NoError(t, m.Set(`example\.com.port`, 8443))
+	source, err = m.Origin(`example\.com.port`)
+	require.NoError(t, err)
+	assert.Equal(t, OriginSet, source.Kind)
+}
+
+func TestManager_Explain_History(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "10-base.yaml")
+	local := filepath.Join(dir, "20-local.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("server:\n  port: 8080\n  host: localhost\n"), 0644))
+	require.NoError(t, os.WriteFile(local, []byte("server:\n  port: 9090\n"), 0644))
+
+	m := New()
+	require.NoError(t, m.LoadDir(dir))
+	require.NoError(t, m.MergeMap(map[string]interface{}{"server": map[string]interface{}{"port": 7070}}))
+	require.NoError(t, m.Set("server.port", 6060))
+
+	sources, err := m.Explain("server.port")
+	require.NoError(t, err)
+	require.Len(t, sources, 4)
+	assert.Equal(t, base, sources[0].File)
+	assert.Equal(t, 8080, sources[0].Value)
+	assert.Equal(t, local, sources[1].File)
+	assert.Equal(t, 9090, sources[1].Value)
+	assert.Equal(t, OriginMerge, sources[2].Kind)
+	assert.Equal(t, 7070, sources[2].Value)
+	assert.Equal(t, OriginSet, sources[3].Kind)
+	assert.Equal(t, 6060, sources[3].Value)
+
+	sources, err = m.Explain("server.host")
+	require.NoError(t, err)
+	require.Len(t, sources, 1, "overriding a key should not touch the history of its siblings")
+	assert.Equal(t, base, sources[0].File)
+
+	require.NoError(t, m.Set("server", map[string]interface{}{"host": "example.com"}))
+	sources, err = m.Explain("server.host")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	assert.Equal(t, OriginSet, sources[1].Kind)
+	_, err = m.Explain("server.port")
+	assert.Error(t, err, "keys replaced by Set should lose their history")
+
+	require.NoError(t, m.Set("server.host.name", "example.com"))
+	assert.NotContains(t, m.origins, "server.host", "a scalar replaced by a map should lose its history")
+	assert.Contains(t, m.origins, "server.host.name")
+
+	require.NoError(t, m.Delete("server.host"))
+	assert.NotContains(t, m.origins, "server.host.name")
+}
+
+func TestManager_Origin_SensitiveKeys(t *testing.T) {
+	t.Setenv("REDACT_TEST_API_KEY", "from-env")
+
+	m := New(WithEnvPrefix("REDACT_TEST"), WithSensitiveKeys("database.password", "api_key"))
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"database": map[string]interface{}{"password": "hunter2", "host": "db"},
+		"token":    NewSecret("s3cr3t"),
+	}))
+	require.NoError(t, m.Set("database.password", "hunter3"))
+
+	for _, key := range []string{"database.password", "token", "api_key"} {
+		source, err := m.Origin(key)
+		require.NoError(t, err)
+		assert.Equal(t, RedactedValue, source.Value, key)
+
+		sources, err := m.Explain(key)
+		require.NoError(t, err)
+		for _, source := range sources {
+			assert.Equal(t, RedactedValue, source.Value, key)
+		}
+	}
+
+	source, err := m.Origin("database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db", source.Value)
+}
@@ -0,0 +1,500 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a single step of a parsed key path.
+type segment struct {
+	key      string // Map key, or the text of a dotted segment such as "0"
+	index    int    // Slice index of a bracketed segment, negative values count from the end
+	isIndex  bool   // Whether the segment is a bracketed index such as [0]
+	wildcard bool   // Whether the segment matches every element of a slice or map
+}
+
+// parseKey splits a key path into segments. Segments are separated by dots, and slice
+// elements can be addressed with dotted numbers (servers.0.host), bracketed indices
+// (servers[0].host), negative indices (servers[-1]) and wildcards (servers[*].host or
+// servers.*.host). Keys containing dots or brackets can be escaped with a backslash
+// (hosts.example\.com) or quoted in brackets (hosts["example.com"] or hosts['example.com']).
+func parseKey(key string) ([]segment, error) {
+	var segments []segment
+	var current strings.Builder
+	escaped := false
+	expectSegment := true
+
+	flush := func(pos int) error {
+		if current.Len() == 0 && !escaped {
+			if expectSegment {
+				return fmt.Errorf("empty key segment at position %d in '%s'", pos, key)
+			}
+			return nil
+		}
+
+		name := current.String()
+		segments = append(segments, segment{key: name, wildcard: name == "*" && !escaped})
+		current.Reset()
+		escaped = false
+		return nil
+	}
+
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '\\':
+			if i+1 >= len(key) {
+				return nil, fmt.Errorf("trailing '\\' in '%s'", key)
+			}
+			i++
+			current.WriteByte(key[i])
+			escaped = true
+			expectSegment = false
+		case '.':
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			expectSegment = true
+		case '[':
+			if current.Len() > 0 || escaped {
+				if err := flush(i); err != nil {
+					return nil, err
+				}
+			} else if expectSegment && len(segments) > 0 {
+				return nil, fmt.Errorf("empty key segment at position %d in '%s'", i, key)
+			}
+
+			seg, end, err := parseBracket(key, i)
+			if err != nil {
+				return nil, err
+			}
+
+			segments = append(segments, seg)
+			i = end
+			expectSegment = false
+
+			if i+1 < len(key) && key[i+1] != '.' && key[i+1] != '[' {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d in '%s'", key[i+1], i+1, key)
+			}
+		case ']':
+			return nil, fmt.Errorf("unexpected ']' at position %d in '%s'", i, key)
+		default:
+			current.WriteByte(c)
+			expectSegment = false
+		}
+	}
+
+	if err := flush(len(key)); err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return nil, errors.New("invalid path")
+	}
+
+	return segments, nil
+}
+
+// parseBracket parses the bracketed segment starting at key[start], which is '['.
+// It returns the segment and the position of the closing ']'.
+func parseBracket(key string, start int) (segment, int, error) {
+	i := start + 1
+	if i < len(key) && (key[i] == '"' || key[i] == '\'') {
+		quote := key[i]
+		var name strings.Builder
+
+		for i++; i < len(key) && key[i] != quote; i++ {
+			if key[i] == '\\' && i+1 < len(key) {
+				i++
+			}
+			name.WriteByte(key[i])
+		}
+
+		if i >= len(key) {
+			return segment{}, 0, fmt.Errorf("unterminated quote at position %d in '%s'", start+1, key)
+		}
+
+		if i+1 >= len(key) || key[i+1] != ']' {
+			return segment{}, 0, fmt.Errorf("expected ']' at position %d in '%s'", i+1, key)
+		}
+
+		return segment{key: name.String()}, i + 1, nil
+	}
+
+	end := strings.IndexByte(key[start:], ']')
+	if end < 0 {
+		return segment{}, 0, fmt.Errorf("unterminated '[' at position %d in '%s'", start, key)
+	}
+
+	seg, err := parseIndex(key[start+1 : start+end])
+	if err != nil {
+		return segment{}, 0, fmt.Errorf("%w at position %d in '%s'", err, start, key)
+	}
+
+	return seg, start + end, nil
+}
+
+// parseIndex parses the content of an unquoted bracketed segment.
+func parseIndex(content string) (segment, error) {
+	content = strings.TrimSpace(content)
+	if content == "*" {
+		return segment{key: "*", isIndex: true, wildcard: true}, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, fmt.Errorf("invalid index '%s'", content)
+	}
+
+	return segment{key: content, index: index, isIndex: true}, nil
+}
+
+// escapeKey escapes the characters of a raw key that have a meaning in key paths.
+func escapeKey(key string) string {
+	if !strings.ContainsAny(key, `.[]\`) && key != "*" {
+		return key
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '.', '[', ']', '\\':
+			b.WriteByte('\\')
+		case '*':
+			if len(key) == 1 {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
+
+// NewKeyPath builds a key path from raw map keys. Keys are used as-is, so keys containing
+// dots or brackets don't need escaping.
+func NewKeyPath(keys ...string) KeyPath {
+	path := KeyPath{}
+	for _, key := range keys {
+		path = path.Key(key)
+	}
+	return path
+}
+
+// ParseKeyPath parses a key path string such as `hosts["example.com"].port`.
+func ParseKeyPath(key string) (KeyPath, error) {
+	segments, err := parseKey(key)
+	if err != nil {
+		return KeyPath{}, &ConfigError{
+			Operation: "parse key",
+			Key:       key,
+			Err:       err,
+		}
+	}
+	return KeyPath{segments: segments}, nil
+}
+
+// Key returns a copy of the path with a raw map key appended.
+func (p KeyPath) Key(key string) KeyPath {
+	return p.with(segment{key: key})
+}
+
+// Index returns a copy of the path with a slice index appended. Negative indices count
+// from the end of the slice.
+func (p KeyPath) Index(index int) KeyPath {
+	return p.with(segment{key: strconv.Itoa(index), index: index, isIndex: true})
+}
+
+// Wildcard returns a copy of the path with a wildcard appended.
+func (p KeyPath) Wildcard() KeyPath {
+	return p.with(segment{key: "*", isIndex: true, wildcard: true})
+}
+
+// String returns the path in the syntax accepted by Get, Set and Delete, escaping keys
+// where needed.
+func (p KeyPath) String() string {
+	var b strings.Builder
+	for i, seg := range p.segments {
+		switch {
+		case seg.isIndex:
+			b.WriteString("[" + seg.key + "]")
+		case i > 0:
+			b.WriteString("." + escapeKey(seg.key))
+		default:
+			b.WriteString(escapeKey(seg.key))
+		}
+	}
+	return b.String()
+}
+
+// with returns a copy of the path with a segment appended.
+func (p KeyPath) with(seg segment) KeyPath {
+	segments := make([]segment, len(p.segments), len(p.segments)+1)
+	copy(segments, p.segments)
+	return KeyPath{segments: append(segments, seg)}
+}
+
+// hasWildcard reports whether any segment is a wildcard.
+func hasWildcard(segments []segment) bool {
+	for _, seg := range segments {
+		if seg.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// sliceIndex returns the slice index addressed by a segment, resolving negative indices
+// against the slice length.
+func (s segment) sliceIndex(length int) (int, bool) {
+	index := s.index
+	if !s.isIndex {
+		parsed, err := strconv.Atoi(s.key)
+		if err != nil {
+			return 0, false
+		}
+		index = parsed
+	}
+
+	if index < 0 {
+		index += length
+	}
+
+	return index, index >= 0
+}
+
+// mapKey returns the key of m addressed by a segment, matching existing keys
+// case-insensitively if requested.
+func (s segment) mapKey(m map[string]interface{}, caseSensitive bool) string {
+	if _, exists := m[s.key]; exists || caseSensitive {
+		return s.key
+	}
+
+	for k := range m {
+		if strings.EqualFold(k, s.key) {
+			return k
+		}
+	}
+
+	return s.key
+}
+
+// asStringMap returns v as a map with string keys, converting maps with interface keys.
+func asStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return x, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			strKey, ok := k.(string)
+			if !ok {
+				strKey = fmt.Sprintf("%v", k)
+			}
+			m[strKey] = val
+		}
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
+// lookupPath collects every value matched by segments below value, together with its
+// concrete dotted key. It returns an error describing the first segment that cannot be
+// resolved when nothing matches.
+func lookupPath(value interface{}, segments []segment, path string, caseSensitive bool, visit func(key string, value interface{})) error {
+	if len(segments) == 0 {
+		visit(path, value)
+		return nil
+	}
+
+	seg, rest := segments[0], segments[1:]
+
+	if m, ok := asStringMap(value); ok {
+		if seg.wildcard {
+			var firstErr error
+			for _, k := range sortedKeys(m) {
+				if err := lookupPath(m[k], rest, joinKey(path, k), caseSensitive, visit); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+			return firstErr
+		}
+
+		key := seg.mapKey(m, caseSensitive)
+		child, exists := m[key]
+		if !exists {
+			return fmt.Errorf("key '%s' not found", joinKey(path, seg.key))
+		}
+		return lookupPath(child, rest, joinKey(path, key), caseSensitive, visit)
+	}
+
+	if slice, ok := value.([]interface{}); ok {
+		if seg.wildcard {
+			var firstErr error
+			for i, item := range slice {
+				if err := lookupPath(item, rest, joinKey(path, strconv.Itoa(i)), caseSensitive, visit); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+			return firstErr
+		}
+
+		index, ok := seg.sliceIndex(len(slice))
+		if !ok || index >= len(slice) {
+			return fmt.Errorf("index '%s' out of range in '%s'", seg.key, path)
+		}
+		return lookupPath(slice[index], rest, joinKey(path, strconv.Itoa(index)), caseSensitive, visit)
+	}
+
+	return fmt.Errorf("key '%s' is not a map or slice", path)
+}
+
+// setPath stores value at the location addressed by segments below container, creating
+// maps and slices as needed, and returns the updated container. Setting the index just past
+// the end of a slice appends to it, while larger indices are out of range.
+func setPath(container interface{}, segments []segment, value interface{}, caseSensitive bool) (interface{}, error) {
+	seg, rest := segments[0], segments[1:]
+
+	if container == nil {
+		if seg.isIndex && !seg.wildcard {
+			container = []interface{}{}
+		} else {
+			container = make(map[string]interface{})
+		}
+	}
+
+	setChild := func(child interface{}) (interface{}, error) {
+		if len(rest) == 0 {
+			return value, nil
+		}
+		if _, isMap := asStringMap(child); !isMap {
+			if _, isSlice := child.([]interface{}); !isSlice {
+				child = nil
+			}
+		}
+		return setPath(child, rest, value, caseSensitive)
+	}
+
+	if m, ok := asStringMap(container); ok {
+		keys := []string{seg.mapKey(m, caseSensitive)}
+		if seg.wildcard {
+			keys = sortedKeys(m)
+		}
+
+		for _, k := range keys {
+			child, err := setChild(m[k])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = child
+		}
+		return m, nil
+	}
+
+	slice, ok := container.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot set '%s' on %T", seg.key, container)
+	}
+
+	if seg.wildcard {
+		for i := range slice {
+			child, err := setChild(slice[i])
+			if err != nil {
+				return nil, err
+			}
+			slice[i] = child
+		}
+		return slice, nil
+	}
+
+	index, ok := seg.sliceIndex(len(slice))
+	if !ok || index > len(slice) {
+		return nil, fmt.Errorf("index '%s' out of range for %d element(s)", seg.key, len(slice))
+	}
+
+	if index == len(slice) {
+		slice = append(slice, nil)
+	}
+
+	child, err := setChild(slice[index])
+	if err != nil {
+		return nil, err
+	}
+	slice[index] = child
+	return slice, nil
+}
+
+// deletePath removes the values addressed by segments below container and returns the
+// updated container and the number of removed values. Deleting a slice element shrinks
+// the slice.
+func deletePath(container interface{}, segments []segment, caseSensitive bool) (interface{}, int, error) {
+	seg, rest := segments[0], segments[1:]
+
+	if m, ok := asStringMap(container); ok {
+		keys := []string{seg.mapKey(m, caseSensitive)}
+		if seg.wildcard {
+			keys = sortedKeys(m)
+		}
+
+		removed := 0
+		for _, k := range keys {
+			child, exists := m[k]
+			if !exists {
+				continue
+			}
+
+			if len(rest) == 0 {
+				delete(m, k)
+				removed++
+				continue
+			}
+
+			updated, n, err := deletePath(child, rest, caseSensitive)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = updated
+			removed += n
+		}
+		return m, removed, nil
+	}
+
+	slice, ok := container.([]interface{})
+	if !ok {
+		return container, 0, nil
+	}
+
+	if seg.wildcard {
+		if len(rest) == 0 {
+			return slice[:0], len(slice), nil
+		}
+
+		removed := 0
+		for i := range slice {
+			updated, n, err := deletePath(slice[i], rest, caseSensitive)
+			if err != nil {
+				return nil, 0, err
+			}
+			slice[i] = updated
+			removed += n
+		}
+		return slice, removed, nil
+	}
+
+	index, ok := seg.sliceIndex(len(slice))
+	if !ok || index >= len(slice) {
+		return slice, 0, nil
+	}
+
+	if len(rest) == 0 {
+		return append(slice[:index], slice[index+1:]...), 1, nil
+	}
+
+	updated, removed, err := deletePath(slice[index], rest, caseSensitive)
+	if err != nil {
+		return nil, 0, err
+	}
+	slice[index] = updated
+	return slice, removed, nil
+}
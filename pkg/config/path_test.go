@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestManager_IndexPaths(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{
+		"servers": [
+			{"host": "a.example.com", "port": 80},
+			{"host": "b.example.com", "port": 81},
+			{"host": "c.example.com"}
+		]
+	}`), FormatJSON))
+
+	for key, want := range map[string]string{
+		"servers.0.host":   "a.example.com",
+		"servers[1].host":  "b.example.com",
+		"servers[-1].host": "c.example.com",
+	} {
+		host, err := m.GetString(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, want, host, key)
+	}
+
+	hosts, err := m.GetStringSlice("servers[*].host")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, hosts)
+
+	ports, err := m.Get("servers.*.port")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(80), float64(81)}, ports, "wildcards should skip elements without the key")
+
+	_, err = m.Get("servers[3].host")
+	assert.Error(t, err)
+	_, err = m.Get("servers[x]")
+	assert.Error(t, err)
+
+	require.NoError(t, m.Set("servers[3].host", "d.example.com"))
+	host, err := m.GetString("servers[-1].host")
+	require.NoError(t, err)
+	assert.Equal(t, "d.example.com", host, "Set should grow slices")
+
+	err = m.Set("servers[999999999].host", "x")
+	var configErr *ConfigError
+	require.True(t, errors.As(err, &configErr), "Set should reject indices past the end of a slice")
+	assert.Equal(t, "set", configErr.Operation)
+	assert.Error(t, m.Set("ports[5]", 80), "Set should not pad new slices")
+	servers, err := m.Get("servers")
+	require.NoError(t, err)
+	assert.Len(t, servers, 4)
+
+	require.NoError(t, m.Set("servers[*].tls", true))
+	tls, err := m.Get("servers[*].tls")
+	require.NoError(t, err)
+	assert.Len(t, tls, 4)
+
+	require.NoError(t, m.Set("tags[0]", "first"))
+	tags, err := m.GetStringSlice("tags")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, tags, "Set should create slices for index segments")
+
+	require.NoError(t, m.Delete("servers[0]"))
+	host, err = m.GetString("servers[0].host")
+	require.NoError(t, err)
+	assert.Equal(t, "b.example.com", host, "Delete should shrink slices")
+
+	require.NoError(t, m.Delete("servers[*].port"))
+	assert.False(t, m.Has("servers[0].port"))
+	assert.Error(t, m.Delete("servers[10]"))
+}
+
+func TestManager_EscapedPaths(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{"hosts": {"example.com": {"port": 443}, "a[1]": "bracket"}}`), FormatJSON))
+
+	for _, key := range []string{`hosts.example\.com.port`, `hosts["example.com"].port`, `hosts['example.com'].port`} {
+		port, err := m.GetInt(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, 443, port, key)
+	}
+
+	value, err := m.GetString(`hosts.a\[1\]`)
+	require.NoError(t, err)
+	assert.Equal(t, "bracket", value)
+
+	path := NewKeyPath("hosts", "example.com").Key("port")
+	assert.Equal(t, `hosts.example\.com.port`, path.String())
+	port, err := m.GetInt(path.String())
+	require.NoError(t, err)
+	assert.Equal(t, 443, port)
+
+	require.NoError(t, m.Set(NewKeyPath("hosts", "api.example.com", "ports").Index(0).String(), 8443))
+	port, err = m.GetInt(`hosts["api.example.com"].ports[0]`)
+	require.NoError(t, err)
+	assert.Equal(t, 8443, port)
+
+	parsed, err := ParseKeyPath(`hosts["example.com"].ports[-1]`)
+	require.NoError(t, err)
+	assert.Equal(t, `hosts.example\.com.ports[-1]`, parsed.String())
+
+	for _, invalid := range []string{`hosts["example.com`, `hosts..port`, `hosts\`, `hosts]`} {
+		_, err := ParseKeyPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,705 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query evaluates a JSONPath-style expression against the configuration data and returns
+// the matching values. See QueryMatches for the supported syntax.
+func (m *Manager) Query(expr string) ([]interface{}, error) {
+	matches, err := m.QueryMatches(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(matches))
+	for i, match := range matches {
+		values[i] = match.Value
+	}
+	return values, nil
+}
+
+// QueryMatches evaluates a JSONPath-style expression against the configuration data and
+// returns the matching values together with their concrete dotted keys. The expression
+// supports the root ($), child names (.name, ['name']), wildcards (.*, [*]), indices ([0],
+// [-1]), unions ([0,2], ['a','b']), slices ([start:end:step]), recursive descent (..name)
+// and filters such as [?(@.weight > 5 && @.enabled)] with the operators ==, !=, <, <=, >,
+// >=, =~ (regular expression match), &&, || and !.
+func (m *Manager) QueryMatches(expr string) ([]Match, error) {
+	q, err := compileQuery(expr)
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "query",
+			Err:       err,
+		}
+	}
+
+	data, err := m.Get("")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := q.evaluate([]Match{{Key: "", Value: data}}, data)
+	if nodes == nil {
+		nodes = []Match{}
+	}
+	return nodes, nil
+}
+
+// query is a compiled JSONPath expression.
+type query []queryStep
+
+// queryStep selects nodes from the children of every input node, or from every descendant
+// of the input nodes when descendant is set.
+type queryStep struct {
+	descendant bool
+	selectors  []querySelector
+}
+
+// querySelector selects children of a node.
+type querySelector func(node Match, root interface{}) []Match
+
+// queryParser is a recursive descent parser for JSONPath expressions.
+type queryParser struct {
+	src string
+	pos int
+}
+
+// compileQuery parses a JSONPath expression. A leading '$' is optional.
+func compileQuery(expr string) (query, error) {
+	p := &queryParser{src: strings.TrimSpace(expr)}
+	if p.src == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	if p.peek() == '$' {
+		p.pos++
+	} else if p.peek() != '.' && p.peek() != '[' {
+		p.src = "." + p.src
+	}
+
+	steps, err := p.parseSteps()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected character '%c'", p.src[p.pos])
+	}
+
+	return steps, nil
+}
+
+// evaluate applies the query to the input nodes.
+func (q query) evaluate(nodes []Match, root interface{}) []Match {
+	for _, step := range q {
+		var next []Match
+		for _, node := range nodes {
+			candidates := []Match{node}
+			if step.descendant {
+				candidates = descendants(node)
+			}
+			for _, candidate := range candidates {
+				for _, sel := range step.selectors {
+					next = append(next, sel(candidate, root)...)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d in '%s'", fmt.Sprintf(format, args...), p.pos, p.src)
+}
+
+// parseSteps parses a sequence of .name, ..name and [...] steps.
+func (p *queryParser) parseSteps() (query, error) {
+	var steps query
+
+	for p.pos < len(p.src) {
+		var step queryStep
+
+		switch p.peek() {
+		case '.':
+			p.pos++
+			if p.peek() == '.' {
+				p.pos++
+				step.descendant = true
+			}
+
+			if p.peek() == '[' {
+				if !step.descendant {
+					return nil, p.errorf("unexpected '['")
+				}
+				selectors, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				step.selectors = selectors
+				break
+			}
+
+			if p.peek() == '*' {
+				p.pos++
+				step.selectors = []querySelector{wildcardSelector}
+				break
+			}
+
+			name := p.parseName()
+			if name == "" {
+				return nil, p.errorf("expected a name")
+			}
+			step.selectors = []querySelector{nameSelector(name)}
+		case '[':
+			selectors, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			step.selectors = selectors
+		default:
+			return steps, nil
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// parseName parses a dotted member name.
+func (p *queryParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' && c != '$' {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseBracket parses a bracketed selector list: names, indices, slices, wildcards and filters.
+func (p *queryParser) parseBracket() ([]querySelector, error) {
+	p.pos++ // '['
+	var selectors []querySelector
+
+	for {
+		p.skipSpaces()
+
+		switch c := p.peek(); {
+		case c == '*':
+			p.pos++
+			selectors = append(selectors, wildcardSelector)
+		case c == '\'' || c == '"':
+			name, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, nameSelector(name))
+		case c == '?':
+			p.pos++
+			p.skipSpaces()
+			filter, err := p.parseFilter()
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, filterSelector(filter))
+		case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+			sel, err := p.parseIndexOrSlice()
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, sel)
+		default:
+			return nil, p.errorf("unexpected character in brackets")
+		}
+
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseString parses a single- or double-quoted string with backslash escapes.
+func (p *queryParser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.src) && p.src[p.pos] != quote {
+		if p.src[p.pos] == '\\' && p.pos+1 < len(p.src) {
+			p.pos++
+		}
+		b.WriteByte(p.src[p.pos])
+		p.pos++
+	}
+
+	if p.pos >= len(p.src) {
+		return "", p.errorf("unterminated string")
+	}
+
+	p.pos++
+	return b.String(), nil
+}
+
+// parseInt parses an optional signed integer.
+func (p *queryParser) parseInt() (int, bool, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+
+	if start == p.pos {
+		return 0, false, nil
+	}
+
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid integer '%s'", p.src[start:p.pos])
+	}
+	return n, true, nil
+}
+
+// parseIndexOrSlice parses [n] or [start:end:step].
+func (p *queryParser) parseIndexOrSlice() (querySelector, error) {
+	var bounds [3]*int
+
+	for part := 0; part < 3; part++ {
+		p.skipSpaces()
+		n, ok, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			value := n
+			bounds[part] = &value
+		}
+
+		p.skipSpaces()
+		if p.peek() != ':' {
+			if part == 0 {
+				if !ok {
+					return nil, p.errorf("expected an index")
+				}
+				return indexSelector(n), nil
+			}
+			break
+		}
+		p.pos++
+	}
+
+	return sliceSelector(bounds[0], bounds[1], bounds[2])
+}
+
+// filterExpr evaluates a filter against a candidate node.
+type filterExpr func(node Match, root interface{}) bool
+
+// parseFilter parses a filter expression, with or without surrounding parentheses.
+func (p *queryParser) parseFilter() (filterExpr, error) {
+	return p.parseOr()
+}
+
+func (p *queryParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.src[p.pos:], "||") {
+			return left, nil
+		}
+		p.pos += 2
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(node Match, root interface{}) bool {
+			return l(node, root) || right(node, root)
+		}
+	}
+}
+
+func (p *queryParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.src[p.pos:], "&&") {
+			return left, nil
+		}
+		p.pos += 2
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(node Match, root interface{}) bool {
+			return l(node, root) && right(node, root)
+		}
+	}
+}
+
+func (p *queryParser) parseUnary() (filterExpr, error) {
+	p.skipSpaces()
+
+	if p.peek() == '!' && !strings.HasPrefix(p.src[p.pos:], "!=") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(node Match, root interface{}) bool {
+			return !inner(node, root)
+		}, nil
+	}
+
+	if p.peek() == '(' {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+// filterOperand evaluates to the values an operand stands for.
+type filterOperand func(node Match, root interface{}) []interface{}
+
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *queryParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	op := ""
+	for _, candidate := range comparisonOperators {
+		if strings.HasPrefix(p.src[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	if op == "" {
+		// A bare operand tests for existence.
+		return func(node Match, root interface{}) bool {
+			return len(left(node, root)) > 0
+		}, nil
+	}
+	p.pos += len(op)
+
+	p.skipSpaces()
+	if op == "=~" {
+		if p.peek() != '\'' && p.peek() != '"' {
+			return nil, p.errorf("expected a regular expression string")
+		}
+		pattern, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, p.errorf("invalid regular expression: %v", err)
+		}
+		return func(node Match, root interface{}) bool {
+			for _, v := range left(node, root) {
+				if s, ok := v.(string); ok && re.MatchString(s) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(node Match, root interface{}) bool {
+		for _, l := range left(node, root) {
+			for _, r := range right(node, root) {
+				if compareValues(l, r, op) {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+// parseOperand parses a relative (@) or absolute ($) path, or a literal.
+func (p *queryParser) parseOperand() (filterOperand, error) {
+	p.skipSpaces()
+
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		steps, err := p.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		relative := c == '@'
+		return func(node Match, root interface{}) []interface{} {
+			start := Match{Value: root}
+			if relative {
+				start = node
+			}
+			var values []interface{}
+			for _, match := range steps.evaluate([]Match{start}, root) {
+				values = append(values, match.Value)
+			}
+			return values
+		}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand(s), nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number '%s'", p.src[start:p.pos])
+		}
+		return literalOperand(n), nil
+	}
+
+	for literal, value := range map[string]interface{}{"true": true, "false": false, "null": nil} {
+		if strings.HasPrefix(p.src[p.pos:], literal) {
+			p.pos += len(literal)
+			return literalOperand(value), nil
+		}
+	}
+
+	return nil, p.errorf("expected an operand")
+}
+
+// literalOperand returns an operand that always evaluates to value.
+func literalOperand(value interface{}) filterOperand {
+	return func(Match, interface{}) []interface{} {
+		return []interface{}{value}
+	}
+}
+
+// compareValues compares two values with a comparison operator. Ordering operators apply
+// to numbers and strings only.
+func compareValues(left, right interface{}, op string) bool {
+	switch op {
+	case "==":
+		return jsonEqual(left, right)
+	case "!=":
+		return !jsonEqual(left, right)
+	}
+
+	var cmp int
+	if l, ok := toNumber(left); ok {
+		r, ok := toNumber(right)
+		if !ok {
+			return false
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	} else if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(l, r)
+	} else {
+		return false
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// children returns the direct children of a node, map entries in key order.
+func children(node Match) []Match {
+	if m, ok := asStringMap(node.Value); ok {
+		result := make([]Match, 0, len(m))
+		for _, k := range sortedKeys(m) {
+			result = append(result, Match{Key: joinKey(node.Key, k), Value: m[k]})
+		}
+		return result
+	}
+
+	if slice, ok := node.Value.([]interface{}); ok {
+		result := make([]Match, len(slice))
+		for i, v := range slice {
+			result[i] = Match{Key: joinKey(node.Key, strconv.Itoa(i)), Value: v}
+		}
+		return result
+	}
+
+	return nil
+}
+
+// descendants returns a node and all of its descendants in document order.
+func descendants(node Match) []Match {
+	result := []Match{node}
+	for _, child := range children(node) {
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
+
+func wildcardSelector(node Match, _ interface{}) []Match {
+	return children(node)
+}
+
+func nameSelector(name string) querySelector {
+	return func(node Match, _ interface{}) []Match {
+		m, ok := asStringMap(node.Value)
+		if !ok {
+			return nil
+		}
+		value, exists := m[name]
+		if !exists {
+			return nil
+		}
+		return []Match{{Key: joinKey(node.Key, name), Value: value}}
+	}
+}
+
+func indexSelector(index int) querySelector {
+	return func(node Match, _ interface{}) []Match {
+		slice, ok := node.Value.([]interface{})
+		if !ok {
+			return nil
+		}
+		i := index
+		if i < 0 {
+			i += len(slice)
+		}
+		if i < 0 || i >= len(slice) {
+			return nil
+		}
+		return []Match{{Key: joinKey(node.Key, strconv.Itoa(i)), Value: slice[i]}}
+	}
+}
+
+func sliceSelector(start, end, step *int) (querySelector, error) {
+	stride := 1
+	if step != nil {
+		stride = *step
+	}
+	if stride == 0 {
+		return nil, fmt.Errorf("slice step cannot be zero")
+	}
+
+	return func(node Match, _ interface{}) []Match {
+		slice, ok := node.Value.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		length := len(slice)
+		normalize := func(bound *int, def int) int {
+			if bound == nil {
+				return def
+			}
+			n := *bound
+			if n < 0 {
+				n += length
+			}
+			return n
+		}
+
+		var result []Match
+		if stride > 0 {
+			from := max(normalize(start, 0), 0)
+			to := min(normalize(end, length), length)
+			for i := from; i < to; i += stride {
+				result = append(result, Match{Key: joinKey(node.Key, strconv.Itoa(i)), Value: slice[i]})
+			}
+		} else {
+			from := min(normalize(start, length-1), length-1)
+			to := max(normalize(end, -1), -1)
+			for i := from; i > to; i += stride {
+				result = append(result, Match{Key: joinKey(node.Key, strconv.Itoa(i)), Value: slice[i]})
+			}
+		}
+		return result
+	}, nil
+}
+
+func filterSelector(filter filterExpr) querySelector {
+	return func(node Match, root interface{}) []Match {
+		var result []Match
+		for _, child := range children(node) {
+			if filter(child, root) {
+				result = append(result, child)
+			}
+		}
+		return result
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestManager_Query(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`
+upstreams:
+  - name: api
+    weight: 3
+    tags: [internal]
+  - name: web
+    weight: 7
+    tags: [public]
+  - name: admin
+    weight: 9
+    enabled: false
+limits:
+  upstreams:
+    - name: nested
+      weight: 1
+`), FormatYAML))
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{"$.upstreams[?(@.weight > 5)].name", []interface{}{"web", "admin"}},
+		{"$.upstreams[?(@.weight > 5 && !@.enabled)].name", []interface{}{"web"}},
+		{"$.upstreams[?(@.name == 'api' || @.name =~ '^ad')].weight", []interface{}{3, 9}},
+		{"$.upstreams[?(@.tags)].name", []interface{}{"api", "web"}},
+		{"$..upstreams[*].name", []interface{}{"api", "web", "admin", "nested"}},
+		{"$.upstreams[-1].name", []interface{}{"admin"}},
+		{"$.upstreams[0:2].name", []interface{}{"api", "web"}},
+		{"$.upstreams[::-1].name", []interface{}{"admin", "web", "api"}},
+		{"upstreams[0,2]['name']", []interface{}{"api", "admin"}},
+		{"$.missing", []interface{}{}},
+	}
+
+	for _, tt := range tests {
+		values, err := m.Query(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, values, tt.expr)
+	}
+
+	matches, err := m.QueryMatches("$..[?(@.weight < 5)].name")
+	require.NoError(t, err)
+	assert.Equal(t, []Match{
+		{Key: "limits.upstreams.0.name", Value: "nested"},
+		{Key: "upstreams.0.name", Value: "api"},
+	}, matches)
+
+	for _, invalid := range []string{"", "$.upstreams[", "$.upstreams[?(@.weight >)]", "$.upstreams[::0]"} {
+		_, err := m.Query(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestManager_QueryMatches_DottedKeys(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{
+		"hosts": {"api.example.com": {"port": 443}, "db.example.com": {"port": 5432}},
+		"a.b": {"c": 1}
+	}`), FormatJSON))
+
+	for _, expr := range []string{"$.hosts.*.port", "$.*.c", "$..port"} {
+		matches, err := m.QueryMatches(expr)
+		require.NoError(t, err, expr)
+		require.NotEmpty(t, matches, expr)
+
+		for _, match := range matches {
+			value, err := m.Get(match.Key)
+			require.NoError(t, err, "match key %q should be usable with Get", match.Key)
+			assert.Equal(t, match.Value, value)
+		}
+	}
+
+	matches, err := m.QueryMatches("$.hosts.*.port")
+	require.NoError(t, err)
+	assert.Equal(t, `hosts.api\.example\.com.port`, matches[0].Key)
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	resolverMu sync.RWMutex
+	resolvers  = make(map[string]Resolver)
+)
+
+func init() {
+	resolvers["file"] = fileResolver{}
+	resolvers["env"] = envResolver{}
+}
+
+// WithReferences enables the resolution of secret references in string values read through
+// Get, the typed getters and Bind. A value such as "file:///run/secrets/db_pw" or
+// "env://DB_PASSWORD" is replaced by a Secret holding the content it references, using the
+// Resolver registered for its scheme. Values whose scheme has no resolver are left unchanged.
+func WithReferences(enabled bool) Option {
+	return func(m *Manager) {
+		m.references = enabled
+	}
+}
+
+// WithReferenceTTL caches resolved references for the given duration, so that a reference
+// shared by several keys or read repeatedly is resolved once per period. A zero TTL, the
+// default, resolves references on every read.
+func WithReferenceTTL(ttl time.Duration) Option {
+	return func(m *Manager) {
+		m.referenceTTL = ttl
+	}
+}
+
+// RegisterResolver makes a resolver available to every Manager under the given URI scheme.
+// Registering a resolver for an existing scheme replaces the previous resolver.
+func RegisterResolver(scheme string, resolver Resolver) error {
+	if scheme == "" {
+		return &ConfigError{
+			Operation: "register resolver",
+			Err:       errors.New("scheme cannot be empty"),
+		}
+	}
+
+	if resolver == nil {
+		return &ConfigError{
+			Operation: "register resolver",
+			Err:       fmt.Errorf("resolver for scheme '%s' cannot be nil", scheme),
+		}
+	}
+
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolvers[strings.ToLower(scheme)] = resolver
+
+	return nil
+}
+
+// WithResolver registers a resolver for the given URI scheme on a single Manager.
+// Resolvers registered this way take precedence over the global registry.
+func WithResolver(scheme string, resolver Resolver) Option {
+	return func(m *Manager) {
+		if scheme == "" || resolver == nil {
+			return
+		}
+
+		if m.resolvers == nil {
+			m.resolvers = make(map[string]Resolver)
+		}
+		m.resolvers[strings.ToLower(scheme)] = resolver
+	}
+}
+
+// resolver returns the resolver for the given scheme, preferring resolvers registered on
+// the Manager.
+func (m *Manager) resolver(scheme string) (Resolver, bool) {
+	if resolver, ok := m.resolvers[scheme]; ok {
+		return resolver, true
+	}
+
+	resolverMu.RLock()
+	defer resolverMu.RUnlock()
+
+	resolver, ok := resolvers[scheme]
+	return resolver, ok
+}
+
+// resolveSecrets replaces the secret references in every string within value.
+func (m *Manager) resolveSecrets(key string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return m.resolveSecret(key, v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			resolved, err := m.resolveSecrets(joinKey(key, k), val)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, val := range v {
+			resolved, err := m.resolveSecrets(joinKey(key, strconv.Itoa(i)), val)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveSecret resolves s if it is a reference with a registered scheme.
+func (m *Manager) resolveSecret(key, s string) (interface{}, error) {
+	scheme, ref, ok := strings.Cut(s, "://")
+	if !ok || !validScheme(scheme) {
+		return s, nil
+	}
+
+	resolver, ok := m.resolver(strings.ToLower(scheme))
+	if !ok {
+		return s, nil
+	}
+
+	if value, ok := m.referenceCache.get(s); ok {
+		return NewSecret(value), nil
+	}
+
+	value, err := resolver.Resolve(ref)
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "resolve reference",
+			Key:       key,
+			Err:       fmt.Errorf("%s: %w", scheme, err),
+		}
+	}
+
+	if m.referenceTTL > 0 {
+		m.referenceCache.put(s, value, m.referenceTTL)
+	}
+
+	return NewSecret(value), nil
+}
+
+// validScheme reports whether s is a URI scheme: a letter followed by letters, digits,
+// '+', '-' or '.'.
+func validScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the cached value of a reference, if it has not expired.
+func (c *referenceCache) get(ref string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[ref]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, ref)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// put caches the value of a reference for ttl.
+func (c *referenceCache) put(ref, value string, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[ref] = cachedReference{value: value, expires: time.Now().Add(ttl)}
+}
+
+// fileResolver resolves file:// references to the content of a file, without a trailing
+// newline. file:///run/secrets/db_pw reads /run/secrets/db_pw.
+type fileResolver struct{}
+
+func (fileResolver) Resolve(ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(content)), nil
+}
+
+// envResolver resolves env:// references to the value of an environment variable.
+// env://DB_PASSWORD reads DB_PASSWORD.
+type envResolver struct{}
+
+func (envResolver) Resolve(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", ref)
+	}
+	return value, nil
+}
+
+// ExecResolver resolves exec:// references to the output of a command, without a trailing
+// newline. The reference is split into the command and its arguments on white space, so
+// exec://vault-cli read x runs vault-cli with the arguments read and x. It is not
+// registered by default, as it runs commands named by the configuration; enable it with
+// WithResolver("exec", ExecResolver{}) for trusted configuration files only.
+type ExecResolver struct {
+	Timeout time.Duration // Maximum run time of the command, zero for no limit
+}
+
+// Resolve implements Resolver.
+func (r ExecResolver) Resolve(ref string) (string, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+
+	return trimNewline(string(output)), nil
+}
+
+// trimNewline removes a single trailing line break from s.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeResolver struct {
+	values map[string]string
+	calls  int
+}
+
+func (r *fakeResolver) Resolve(ref string) (string, error) {
+	r.calls++
+	if value, ok := r.values[ref]; ok {
+		return value, nil
+	}
+	return "", errors.New("not found")
+}
+
+func TestManager_References(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_pw")
+	require.NoError(t, os.WriteFile(path, []byte("s3cret\n"), 0600))
+	t.Setenv("REFERENCE_TEST_TOKEN", "t0ken")
+
+	fake := &fakeResolver{values: map[string]string{"db/key": "k3y"}}
+	m := New(WithReferences(true), WithResolver("vault", fake), WithReferenceTTL(time.Minute))
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"password": "file://" + path,
+		"token":    "env://REFERENCE_TEST_TOKEN",
+		"key":      "vault://db/key",
+		"missing":  "vault://db/missing",
+		"site":     "https://example.com",
+	}))
+
+	password, err := m.GetString("password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+
+	token, err := m.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, NewSecret("t0ken"), token, "resolved values should be secrets")
+
+	for i := 0; i < 3; i++ {
+		key, err := m.GetString("key")
+		require.NoError(t, err)
+		assert.Equal(t, "k3y", key)
+	}
+	assert.Equal(t, 1, fake.calls, "resolved references should be cached")
+
+	site, err := m.GetString("site")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", site, "schemes without a resolver should be left unchanged")
+
+	_, err = m.Get("missing")
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "missing", configErr.Key)
+
+	assert.Error(t, m.Bind(&struct{}{}), "Bind should fail on unresolvable references")
+
+	plain := New()
+	require.NoError(t, plain.Set("password", "file://"+path))
+	raw, err := plain.GetString("password")
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+path, raw, "references should only be resolved when enabled")
+}
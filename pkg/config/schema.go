@@ -0,0 +1,468 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// WithSchema attaches a schema to the Manager. The configuration data is validated after
+// Load, LoadFile, Merge and a reload, and before Save; data that fails validation is rejected.
+func WithSchema(schema *Schema) Option {
+	return func(m *Manager) {
+		m.schema = schema
+	}
+}
+
+// ParseSchema compiles a JSON Schema document.
+func ParseSchema(content []byte) (*Schema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, &ConfigError{
+			Operation: "parse schema",
+			Err:       err,
+		}
+	}
+
+	schema, err := compileSchema(doc, "")
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "parse schema",
+			Err:       err,
+		}
+	}
+
+	return schema, nil
+}
+
+// SetSchema attaches a schema to the Manager, replacing any previous schema.
+// A nil schema disables validation.
+func (m *Manager) SetSchema(schema *Schema) {
+	m.schema = schema
+}
+
+// Validate checks the configuration data, as seen by Get without the environment overlay,
+// against the attached schema. Returns a ConfigError wrapping a ValidationError that lists
+// every violation, or nil if no schema is attached.
+func (m *Manager) Validate() error {
+	if m.schema == nil {
+		return nil
+	}
+
+	var violations []Violation
+	m.schema.validate(m.snapshot(), "", &violations, m.IsSensitive)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+
+	return &ConfigError{
+		Operation: "validate",
+		Err:       &ValidationError{Violations: violations},
+	}
+}
+
+// validateData validates the configuration as it would be with data as the manager's own
+// data, leaving the manager unchanged.
+func (m *Manager) validateData(data map[string]interface{}) error {
+	if m.schema == nil {
+		return nil
+	}
+
+	current := m.data
+	m.data = data
+	defer func() {
+		m.data = current
+	}()
+
+	return m.Validate()
+}
+
+// compileSchema compiles a decoded JSON Schema object found at the given JSON pointer.
+func compileSchema(doc interface{}, pointer string) (*Schema, error) {
+	if b, ok := doc.(bool); ok {
+		// true accepts every value, false rejects every value.
+		if b {
+			return &Schema{}, nil
+		}
+		return &Schema{enum: []interface{}{}}, nil
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema at '%s' must be an object", pointerOrRoot(pointer))
+	}
+
+	s := &Schema{}
+	var err error
+
+	switch t := obj["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("'type' at '%s' must contain strings", pointerOrRoot(pointer))
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("'type' at '%s' must be a string or an array", pointerOrRoot(pointer))
+	}
+
+	if enum, exists := obj["enum"]; exists {
+		values, ok := enum.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'enum' at '%s' must be an array", pointerOrRoot(pointer))
+		}
+		s.enum = values
+	}
+
+	if constValue, exists := obj["const"]; exists {
+		s.constValue = constValue
+		s.hasConst = true
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+	} {
+		if *target, err = schemaNumber(obj, keyword, pointer); err != nil {
+			return nil, err
+		}
+	}
+
+	for keyword, target := range map[string]**int{
+		"minLength": &s.minLength,
+		"maxLength": &s.maxLength,
+		"minItems":  &s.minItems,
+		"maxItems":  &s.maxItems,
+	} {
+		if *target, err = schemaCount(obj, keyword, pointer); err != nil {
+			return nil, err
+		}
+	}
+
+	if pattern, exists := obj["pattern"]; exists {
+		str, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("'pattern' at '%s' must be a string", pointerOrRoot(pointer))
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return nil, fmt.Errorf("invalid 'pattern' at '%s': %w", pointerOrRoot(pointer), err)
+		}
+	}
+
+	if properties, exists := obj["properties"]; exists {
+		props, ok := properties.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'properties' at '%s' must be an object", pointerOrRoot(pointer))
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compileSchema(prop, pointer+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if required, exists := obj["required"]; exists {
+		names, ok := required.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'required' at '%s' must be an array", pointerOrRoot(pointer))
+		}
+		for _, v := range names {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("'required' at '%s' must contain strings", pointerOrRoot(pointer))
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if additional, exists := obj["additionalProperties"]; exists {
+		if allowed, ok := additional.(bool); ok {
+			s.noAdditional = !allowed
+		} else if s.additionalProperties, err = compileSchema(additional, pointer+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if items, exists := obj["items"]; exists {
+		if s.items, err = compileSchema(items, pointer+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if writeOnly, exists := obj["writeOnly"]; exists {
+		if s.writeOnly, ok = writeOnly.(bool); !ok {
+			return nil, fmt.Errorf("'writeOnly' at '%s' must be a boolean", pointerOrRoot(pointer))
+		}
+	}
+
+	return s, nil
+}
+
+// schemaNumber reads an optional numeric keyword of a schema object.
+func schemaNumber(obj map[string]interface{}, keyword, pointer string) (*float64, error) {
+	value, exists := obj[keyword]
+	if !exists {
+		return nil, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("'%s' at '%s' must be a number", keyword, pointerOrRoot(pointer))
+	}
+
+	return &number, nil
+}
+
+// schemaCount reads an optional non-negative integer keyword of a schema object.
+func schemaCount(obj map[string]interface{}, keyword, pointer string) (*int, error) {
+	value, exists := obj[keyword]
+	if !exists {
+		return nil, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != math.Trunc(number) {
+		return nil, fmt.Errorf("'%s' at '%s' must be a non-negative integer", keyword, pointerOrRoot(pointer))
+	}
+
+	count := int(number)
+	return &count, nil
+}
+
+// pointerOrRoot returns a printable JSON pointer.
+func pointerOrRoot(pointer string) string {
+	if pointer == "" {
+		return "#"
+	}
+	return "#" + pointer
+}
+
+// validate appends a violation to violations for every constraint value does not satisfy.
+// Values of keys reported by sensitive are replaced by RedactedValue in the messages.
+func (s *Schema) validate(value interface{}, key string, violations *[]Violation, sensitive func(key string) bool) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if secret, ok := value.(Secret); ok {
+		value = secret.value
+	}
+
+	// YAML and TOML decode timestamps as time.Time, which JSON represents as a string.
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+
+	shown := func(v interface{}) interface{} {
+		if s.writeOnly || sensitive(key) {
+			return RedactedValue
+		}
+		return v
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		report("expected %s, got %s", joinTypes(s.types), jsonType(value))
+		return
+	}
+
+	if s.enum != nil && !containsJSONValue(s.enum, value) {
+		if len(s.enum) == 0 {
+			report("no value is allowed")
+		} else {
+			report("value %v is not one of %v", shown(value), s.enum)
+		}
+	}
+
+	if s.hasConst && !jsonEqual(s.constValue, value) {
+		report("value must be %v", s.constValue)
+	}
+
+	if number, ok := toNumber(value); ok {
+		if s.minimum != nil && number < *s.minimum {
+			report("value %v is less than minimum %v", shown(number), *s.minimum)
+		}
+		if s.maximum != nil && number > *s.maximum {
+			report("value %v is greater than maximum %v", shown(number), *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && number <= *s.exclusiveMinimum {
+			report("value %v must be greater than %v", shown(number), *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && number >= *s.exclusiveMaximum {
+			report("value %v must be less than %v", shown(number), *s.exclusiveMaximum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			report("length %d is less than minLength %d", length, *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			report("length %d is greater than maxLength %d", length, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("value %q does not match pattern '%s'", shown(v), s.pattern)
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, exists := v[name]; !exists {
+				*violations = append(*violations, Violation{Key: joinKey(key, name), Message: "required key is missing"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if prop, ok := s.properties[name]; ok {
+				prop.validate(v[name], joinKey(key, name), violations, sensitive)
+			} else if s.noAdditional {
+				*violations = append(*violations, Violation{Key: joinKey(key, name), Message: "additional key is not allowed"})
+			} else if s.additionalProperties != nil {
+				s.additionalProperties.validate(v[name], joinKey(key, name), violations, sensitive)
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			report("%d item(s) is less than minItems %d", len(v), *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			report("%d item(s) is greater than maxItems %d", len(v), *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, joinKey(key, strconv.Itoa(i)), violations, sensitive)
+			}
+		}
+	}
+}
+
+// writeOnlyAt reports whether the schema of the value addressed by segments, or of one of
+// its parents, is marked writeOnly.
+func (s *Schema) writeOnlyAt(segments []segment) bool {
+	if s.writeOnly {
+		return true
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	seg, rest := segments[0], segments[1:]
+	if prop, ok := s.properties[seg.key]; ok && !seg.isIndex {
+		return prop.writeOnly || prop.writeOnlyAt(rest)
+	}
+
+	if _, err := strconv.Atoi(seg.key); (seg.isIndex || err == nil) && s.items != nil {
+		return s.items.writeOnlyAt(rest)
+	}
+
+	if s.additionalProperties != nil {
+		return s.additionalProperties.writeOnlyAt(rest)
+	}
+
+	return false
+}
+
+// matchesAnyType reports whether value has one of the given JSON types.
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinTypes formats a list of JSON types for an error message.
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %v", types)
+}
+
+// jsonType returns the JSON type of a decoded configuration value.
+func jsonType(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+
+	if number, ok := toNumber(value); ok {
+		if number == math.Trunc(number) && !math.IsInf(number, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case string, time.Time:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// toNumber converts any Go numeric value to float64.
+func toNumber(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// jsonEqual compares two decoded values, treating numbers of different Go types as equal
+// when they have the same value.
+func jsonEqual(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// containsJSONValue reports whether values contains a value equal to v.
+func containsJSONValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if jsonEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
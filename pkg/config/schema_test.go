@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["server"],
+	"properties": {
+		"server": {
+			"type": "object",
+			"required": ["port"],
+			"additionalProperties": false,
+			"properties": {
+				"host": {"type": "string", "pattern": "^[a-z.]+$"},
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535}
+			}
+		},
+		"log": {"enum": ["debug", "info", "warn"]},
+		"upstreams": {
+			"type": "array",
+			"minItems": 1,
+			"items": {"type": "object", "required": ["name"]}
+		}
+	}
+}`
+
+func TestManager_Validate(t *testing.T) {
+	schema, err := ParseSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	m := New(WithSchema(schema))
+	require.NoError(t, m.Load(strings.NewReader(`{"server": {"host": "localhost", "port": 8080}, "log": "info"}`), FormatJSON))
+
+	err = m.Load(strings.NewReader(`{
+		"server": {"host": "Local Host", "port": 70000, "tls": true},
+		"log": "trace",
+		"upstreams": [{"weight": 1}]
+	}`), FormatJSON)
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "validation errors should wrap a ValidationError")
+	assert.Equal(t, []Violation{
+		{Key: "log", Message: "value trace is not one of [debug info warn]"},
+		{Key: "server.host", Message: `value "Local Host" does not match pattern '^[a-z.]+$'`},
+		{Key: "server.port", Message: "value 70000 is greater than maximum 65535"},
+		{Key: "server.tls", Message: "additional key is not allowed"},
+		{Key: "upstreams.0.name", Message: "required key is missing"},
+	}, validationErr.Violations)
+
+	port, err := m.GetInt("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port, "invalid data should not replace the current configuration")
+
+	err = m.MergeMap(map[string]interface{}{"server": map[string]interface{}{"port": "http"}})
+	assert.Error(t, err, "MergeMap should reject data that fails validation")
+	port, err = m.GetInt("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port, "a rejected merge should be rolled back")
+
+	require.NoError(t, m.Delete("server.port"))
+	err = m.SaveToFile(filepath.Join(t.TempDir(), "config.json"), FormatJSON)
+	assert.Error(t, err, "SaveToFile should reject data that fails validation")
+
+	_, err = ParseSchema([]byte(`{"pattern": "("}`))
+	assert.Error(t, err, "ParseSchema should reject invalid patterns")
+}
+
+func TestManager_Validate_Timestamps(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"released": {"type": "string", "format": "date-time", "pattern": "^2024-"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	m := New(WithSchema(schema))
+	require.NoError(t, m.Load(strings.NewReader("released = 2024-03-01T12:00:00Z\n"), FormatTOML), "TOML datetimes should validate as strings")
+	require.NoError(t, m.Load(strings.NewReader("released: 2024-03-01T12:00:00Z\n"), FormatYAML), "YAML timestamps should validate as strings")
+
+	err = m.Load(strings.NewReader("released = 2023-03-01T12:00:00Z\n"), FormatTOML)
+	assert.Error(t, err, "timestamps should be checked against string constraints")
+}
@@ -0,0 +1,344 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in Redacted views, error messages and the
+// rendering of Secret values.
+const RedactedValue = "***"
+
+// Delimiters of inline encrypted values, e.g. ENC[AES256_GCM,c2VjcmV0...].
+const (
+	encryptedPrefix = "ENC[AES256_GCM,"
+	encryptedSuffix = "]"
+)
+
+// NewSecret wraps a sensitive value so that it is never printed, logged or marshaled.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the wrapped value.
+func (s Secret) Value() string {
+	return s.value
+}
+
+// String implements fmt.Stringer and returns RedactedValue.
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+// GoString implements fmt.GoStringer, so that %#v does not reveal the value either.
+func (s Secret) GoString() string {
+	return "config.Secret(" + strconv.Quote(RedactedValue) + ")"
+}
+
+// MarshalJSON implements json.Marshaler and renders the secret as RedactedValue.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+// MarshalText implements encoding.TextMarshaler and renders the secret as RedactedValue.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+// MarshalYAML implements yaml.Marshaler and renders the secret as RedactedValue.
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return RedactedValue, nil
+}
+
+// WithSensitiveKeys marks the keys matching any of the given patterns as sensitive, so that
+// their values are hidden by Redacted and in error messages. Patterns use the key path
+// syntax and each segment is matched with filepath.Match, so "database.*.password" matches
+// the password of every database. A single-segment pattern such as "*token*" matches keys
+// with a matching segment at any depth. Keys below a sensitive key are sensitive too.
+// Keys can also be marked sensitive with "writeOnly": true in the schema.
+func WithSensitiveKeys(patterns ...string) Option {
+	return func(m *Manager) {
+		m.sensitiveKeys = append(m.sensitiveKeys, patterns...)
+	}
+}
+
+// WithEncryptionKey sets the 32-byte AES-256 key used to decrypt inline encrypted values
+// such as ENC[AES256_GCM,...] when configuration data is loaded. Decrypted values are
+// stored as Secret values and saved back in their encrypted form; other Secret values are
+// encrypted with the key when saved. Encrypted values can be produced with EncryptValue.
+func WithEncryptionKey(key []byte) Option {
+	return func(m *Manager) {
+		m.encryptionKey = key
+	}
+}
+
+// EncryptValue encrypts a value with a 32-byte AES-256 key, returning an inline encrypted
+// value that a Manager created with WithEncryptionKey decrypts when loading it.
+func EncryptValue(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + encryptedSuffix, nil
+}
+
+// Redacted returns a copy of the configuration data, like Data, in which Secret values and
+// the values of sensitive keys are replaced by RedactedValue. It is safe to print or log.
+func (m *Manager) Redacted() map[string]interface{} {
+	return m.redact(m.Data(), "").(map[string]interface{})
+}
+
+// IsSensitive reports whether the value of a key is hidden by Redacted: the key matches a
+// pattern of WithSensitiveKeys, is marked writeOnly in the schema, or holds a Secret.
+func (m *Manager) IsSensitive(key string) bool {
+	if m.sensitiveKey(key) {
+		return true
+	}
+
+	value, err := m.getRaw(key)
+	if err != nil {
+		return false
+	}
+
+	_, ok := value.(Secret)
+	return ok
+}
+
+// redact returns a copy of value stored under key with sensitive values replaced.
+func (m *Manager) redact(value interface{}, key string) interface{} {
+	if key != "" && m.sensitiveKey(key) {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case Secret:
+		return RedactedValue
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = m.redact(item, joinKey(key, k))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = m.redact(item, joinKey(key, strconv.Itoa(i)))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// sensitiveKey reports whether a key matches a sensitive key pattern or lies within a
+// writeOnly schema.
+func (m *Manager) sensitiveKey(key string) bool {
+	if len(m.sensitiveKeys) == 0 && m.schema == nil {
+		return false
+	}
+
+	segments, err := parseKey(key)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range m.sensitiveKeys {
+		if matchKeyPattern(pattern, segments, m.caseSensitive) {
+			return true
+		}
+	}
+
+	return m.schema != nil && m.schema.writeOnlyAt(segments)
+}
+
+// matchKeyPattern reports whether a sensitive key pattern matches the key made of segments.
+func matchKeyPattern(pattern string, segments []segment, caseSensitive bool) bool {
+	patternSegments, err := parseKey(pattern)
+	if err != nil || len(patternSegments) == 0 {
+		return false
+	}
+
+	match := func(p, s segment) bool {
+		if p.wildcard {
+			return true
+		}
+		pk, sk := p.key, s.key
+		if !caseSensitive {
+			pk, sk = strings.ToLower(pk), strings.ToLower(sk)
+		}
+		matched, _ := filepath.Match(pk, sk)
+		return matched
+	}
+
+	if len(patternSegments) == 1 {
+		for _, s := range segments {
+			if match(patternSegments[0], s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(patternSegments) > len(segments) {
+		return false
+	}
+
+	for i, p := range patternSegments {
+		if !match(p, segments[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// decryptValues replaces the inline encrypted strings within value by Secret values holding
+// their decrypted content, returning the updated value. It does nothing without a key.
+func (m *Manager) decryptValues(value interface{}, key string) (interface{}, error) {
+	if m.encryptionKey == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if !isEncryptedValue(v) {
+			return v, nil
+		}
+
+		plain, err := decryptValue(m.encryptionKey, v)
+		if err != nil {
+			return nil, &ConfigError{
+				Operation: "decrypt",
+				Key:       key,
+				Err:       err,
+			}
+		}
+		return Secret{value: plain, encrypted: v}, nil
+	case map[string]interface{}:
+		for k, item := range v {
+			decrypted, err := m.decryptValues(item, joinKey(key, k))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = decrypted
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			decrypted, err := m.decryptValues(item, joinKey(key, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = decrypted
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// encodableData returns a copy of data in which Secret values are replaced by the encrypted
+// value they were loaded from, by their value encrypted with the manager's key, or by their
+// plain value if the manager has no key.
+func (m *Manager) encodableData(data map[string]interface{}) (map[string]interface{}, error) {
+	var convert func(value interface{}) (interface{}, error)
+	convert = func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case Secret:
+			if v.encrypted != "" {
+				return v.encrypted, nil
+			}
+			if m.encryptionKey != nil {
+				return EncryptValue(m.encryptionKey, v.value)
+			}
+			return v.value, nil
+		case map[string]interface{}:
+			result := make(map[string]interface{}, len(v))
+			for k, item := range v {
+				converted, err := convert(item)
+				if err != nil {
+					return nil, err
+				}
+				result[k] = converted
+			}
+			return result, nil
+		case []interface{}:
+			result := make([]interface{}, len(v))
+			for i, item := range v {
+				converted, err := convert(item)
+				if err != nil {
+					return nil, err
+				}
+				result[i] = converted
+			}
+			return result, nil
+		default:
+			return v, nil
+		}
+	}
+
+	converted, err := convert(data)
+	if err != nil {
+		return nil, err
+	}
+	return converted.(map[string]interface{}), nil
+}
+
+// isEncryptedValue reports whether s is an inline encrypted value.
+func isEncryptedValue(s string) bool {
+	return strings.HasPrefix(s, encryptedPrefix) && strings.HasSuffix(s, encryptedSuffix)
+}
+
+// decryptValue decrypts an inline encrypted value produced by EncryptValue.
+func decryptValue(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix)
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt value: wrong key or corrupted data")
+	}
+
+	return string(plain), nil
+}
+
+// newAEAD returns an AES-256-GCM cipher for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecret_Rendering(t *testing.T) {
+	secret := NewSecret("hunter2")
+
+	assert.Equal(t, "hunter2", secret.Value())
+	assert.Equal(t, RedactedValue, fmt.Sprint(secret))
+	assert.NotContains(t, fmt.Sprintf("%#v", secret), "hunter2")
+	assert.NotContains(t, fmt.Sprintf("%+v", map[string]interface{}{"password": secret}), "hunter2")
+
+	content, err := json.Marshal(map[string]interface{}{"password": secret})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "***"}`, string(content))
+}
+
+func TestManager_Redacted(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"properties": {
+			"api": {"properties": {"key": {"type": "string", "writeOnly": true, "pattern": "^k-"}}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	m := New(WithSensitiveKeys("*password*", "credentials"), WithSchema(schema))
+	require.NoError(t, m.MergeMap(map[string]interface{}{
+		"database":    map[string]interface{}{"host": "db", "password": "s3cret"},
+		"credentials": map[string]interface{}{"user": "admin"},
+		"api":         map[string]interface{}{"key": "k-123"},
+	}))
+	require.NoError(t, m.Set("token", NewSecret("t0ken")))
+
+	assert.Equal(t, map[string]interface{}{
+		"database":    map[string]interface{}{"host": "db", "password": RedactedValue},
+		"credentials": RedactedValue,
+		"api":         map[string]interface{}{"key": RedactedValue},
+		"token":       RedactedValue,
+	}, m.Redacted())
+
+	assert.True(t, m.IsSensitive("credentials.user"))
+	assert.True(t, m.IsSensitive("token"))
+	assert.False(t, m.IsSensitive("database.host"))
+
+	token, err := m.GetString("token")
+	require.NoError(t, err)
+	assert.Equal(t, "t0ken", token)
+
+	err = m.Set("api.key", "leaked")
+	require.NoError(t, err)
+	err = m.Validate()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "leaked", "validation errors should not reveal sensitive values")
+
+	var target struct {
+		Database struct {
+			Password int `cfg:"password"`
+		} `cfg:"database"`
+		Token Secret `cfg:"token"`
+	}
+	err = m.Bind(&target)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cret", "bind errors should not reveal sensitive values")
+	assert.Equal(t, "t0ken", target.Token.Value())
+}
+
+func TestManager_EncryptedValues(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	encrypted, err := EncryptValue(key, "s3cret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "ENC[AES256_GCM,"))
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	original := "database:\n  host: db # primary\n  password: " + encrypted + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(original), 0644))
+
+	m := New(WithEncryptionKey(key))
+	require.NoError(t, m.LoadFile(path))
+
+	value, err := m.Get("database.password")
+	require.NoError(t, err)
+	assert.IsType(t, Secret{}, value)
+
+	password, err := m.GetString("database.password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+
+	var target struct {
+		Database struct {
+			Password Secret `cfg:"password"`
+			Plain    string `cfg:"password"`
+		} `cfg:"database"`
+	}
+	require.NoError(t, m.Bind(&target))
+	assert.Equal(t, "s3cret", target.Database.Password.Value())
+	assert.Equal(t, "s3cret", target.Database.Plain)
+
+	require.NoError(t, m.Save())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "encrypted values should be saved unchanged")
+
+	require.NoError(t, m.Set("api.token", NewSecret("t0ken")))
+	require.NoError(t, m.Save())
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "t0ken", "new secrets should be saved encrypted")
+
+	assert.Error(t, New(WithEncryptionKey([]byte(strings.Repeat("x", 32)))).LoadFile(path),
+		"decrypting with the wrong key should fail")
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Subscribe registers a handler called whenever a value under the given dotted key prefix
+// changes through Set, Delete, Merge, Clear, Load, a layer change or a reload. The handler
+// receives only the changes under the prefix; an empty prefix subscribes to every change.
+// The returned function removes the subscription.
+func (m *Manager) Subscribe(prefix string, handler func(diff Diff)) func() {
+	sub := &subscription{prefix: prefix, handler: handler}
+	m.subscriptions = append(m.subscriptions, sub)
+
+	return func() {
+		for i, s := range m.subscriptions {
+			if s == sub {
+				m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Empty reports whether the diff contains no changes.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// track snapshots the configuration before a mutation and returns a function that notifies
+// subscribers of the changes made since. It does nothing when there are no subscribers.
+func (m *Manager) track() func() {
+	if len(m.subscriptions) == 0 {
+		return func() {}
+	}
+
+	before := flattenLeaves(m.snapshot())
+	return func() {
+		m.notify(diffLeaves(before, flattenLeaves(m.snapshot())))
+	}
+}
+
+// snapshot returns the configuration data as seen by Get, without the environment overlay.
+func (m *Manager) snapshot() map[string]interface{} {
+	if len(m.layers) > 0 {
+		return m.mergedData()
+	}
+	return m.data
+}
+
+// notify calls the handlers of every subscription with changes under its prefix. When
+// deferNotify is set, the calls are queued in pending instead.
+func (m *Manager) notify(diff Diff) {
+	if diff.Empty() {
+		return
+	}
+
+	for _, sub := range m.subscriptions {
+		filtered := diff.filter(sub.prefix, m.caseSensitive)
+		if filtered.Empty() {
+			continue
+		}
+
+		handler := sub.handler
+		if m.deferNotify {
+			m.pending = append(m.pending, func() { handler(filtered) })
+			continue
+		}
+		handler(filtered)
+	}
+}
+
+// update runs fn under the write lock and calls the subscription handlers it triggered once
+// the lock is released, so handlers may safely call back into the manager.
+func (t *ThreadSafeManager) update(fn func()) {
+	t.mu.Lock()
+	t.manager.deferNotify = true
+	fn()
+	pending := t.manager.pending
+	t.manager.pending = nil
+	t.manager.deferNotify = false
+	t.mu.Unlock()
+
+	for _, call := range pending {
+		call()
+	}
+}
+
+// filter returns the changes whose key is the prefix or lies under it.
+func (d Diff) filter(prefix string, caseSensitive bool) Diff {
+	if prefix == "" {
+		return d
+	}
+
+	matches := func(changes []Change) []Change {
+		var result []Change
+		for _, c := range changes {
+			key, p := c.Key, prefix
+			if !caseSensitive {
+				key, p = strings.ToLower(key), strings.ToLower(p)
+			}
+			if key == p || strings.HasPrefix(key, p+".") {
+				result = append(result, c)
+			}
+		}
+		return result
+	}
+
+	return Diff{
+		Added:    matches(d.Added),
+		Removed:  matches(d.Removed),
+		Modified: matches(d.Modified),
+	}
+}
+
+// flattenLeaves returns the leaf values of a nested map keyed by their dotted key.
+// Slices and empty maps are treated as leaves and copied.
+func flattenLeaves(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			for k, v := range nested {
+				walk(joinKey(prefix, k), v)
+			}
+			return
+		}
+		result[prefix] = copyValue(value)
+	}
+
+	for k, v := range data {
+		walk(escapeKey(k), v)
+	}
+
+	return result
+}
+
+// diffLeaves compares two sets of flattened leaves.
+func diffLeaves(before, after map[string]interface{}) Diff {
+	var diff Diff
+
+	for key, old := range before {
+		value, exists := after[key]
+		if !exists {
+			diff.Removed = append(diff.Removed, Change{Key: key, Old: old})
+		} else if !reflect.DeepEqual(old, value) {
+			diff.Modified = append(diff.Modified, Change{Key: key, Old: old, New: value})
+		}
+	}
+
+	for key, value := range after {
+		if _, exists := before[key]; !exists {
+			diff.Added = append(diff.Added, Change{Key: key, New: value})
+		}
+	}
+
+	for _, changes := range [][]Change{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Key < changes[j].Key
+		})
+	}
+
+	return diff
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestManager_Subscribe(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Load(strings.NewReader(`{"database": {"host": "localhost", "port": 5432}, "debug": false}`), FormatJSON))
+
+	var diffs []Diff
+	unsubscribe := m.Subscribe("database", func(diff Diff) {
+		diffs = append(diffs, diff)
+	})
+
+	require.NoError(t, m.Set("debug", true))
+	assert.Empty(t, diffs, "changes outside the prefix should not notify the subscriber")
+
+	require.NoError(t, m.Set("database.host", "db.internal"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, []Change{{Key: "database.host", Old: "localhost", New: "db.internal"}}, diffs[0].Modified)
+
+	m.MergeMap(map[string]interface{}{"database": map[string]interface{}{"user": "app"}})
+	require.Len(t, diffs, 2)
+	assert.Equal(t, []Change{{Key: "database.user", New: "app"}}, diffs[1].Added)
+
+	require.NoError(t, m.Delete("database.port"))
+	require.Len(t, diffs, 3)
+	assert.Equal(t, []Change{{Key: "database.port", Old: float64(5432)}}, diffs[2].Removed)
+
+	unsubscribe()
+	m.Clear()
+	assert.Len(t, diffs, 3, "unsubscribed handlers should not be called")
+}
+
+func TestThreadSafeManager_Subscribe(t *testing.T) {
+	ts := New().ThreadSafe()
+
+	var seen []string
+	ts.Subscribe("", func(diff Diff) {
+		// Handlers run outside the lock and may call back into the manager.
+		value, err := ts.GetString("name")
+		require.NoError(t, err)
+		seen = append(seen, value)
+	})
+
+	require.NoError(t, ts.Set("name", "first"))
+	require.NoError(t, ts.Set("name", "second"))
+	assert.Equal(t, []string{"first", "second"}, seen)
+}
+
+func TestManager_Subscribe_DottedKeys(t *testing.T) {
+	m := New()
+
+	var diffs []Diff
+	m.Subscribe("", func(diff Diff) {
+		diffs = append(diffs, diff)
+	})
+
+	require.NoError(t, m.MergeMap(map[string]interface{}{"example.com": map[string]interface{}{"port": 443}}))
+	require.Len(t, diffs, 1)
+	require.Len(t, diffs[0].Added, 1)
+
+	key := diffs[0].Added[0].Key
+	assert.Equal(t, `example\.com.port`, key)
+	port, err := m.GetInt(key)
+	require.NoError(t, err)
+	assert.Equal(t, 443, port)
+}
@@ -2,7 +2,12 @@ package config
 
 import (
 	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"regexp"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Format represents the supported configuration file formats.
@@ -13,8 +18,173 @@ const (
 	FormatJSON Format = "json"
 	FormatYAML Format = "yaml"
 	FormatYML  Format = "yml"
+	FormatTOML Format = "toml"
 )
 
+// Reserved layer names.
+const (
+	// RuntimeLayer names the Manager's own data, modified by Load, Set and Delete.
+	// It takes precedence over every layer added with AddLayer.
+	RuntimeLayer = "runtime"
+	// EnvLayer names the environment variable overlay enabled by WithEnvPrefix. It takes
+	// precedence over loaded files and over the layers added before it, but not over values
+	// changed with Set. By default it lies above every layer; adding an empty
+	// layer with this name places it on top of the layers added so far, so that layers added
+	// later, such as command-line flags, take precedence over environment variables.
+	EnvLayer = "env"
+)
+
+// IncludeKey is the reserved key listing files that LoadFile loads and deep-merges before
+// the content of the including file. Its value is a path or a list of paths and glob patterns,
+// resolved relative to the directory of the including file.
+const IncludeKey = "$include"
+
+// Tombstone is a value that deletes a key when merged over existing configuration data,
+// allowing an overlay file or layer to remove a key set by a lower one.
+const Tombstone = "$delete"
+
+// SliceStrategy determines how slices are combined when configuration data is merged.
+type SliceStrategy int
+
+// Supported slice merge strategies.
+const (
+	SliceReplace    SliceStrategy = iota // Replace the existing slice (default)
+	SliceAppend                          // Append new elements to the existing slice
+	SliceUnion                           // Append new elements not already present in the existing slice
+	SliceMergeIndex                      // Merge elements at the same index, appending extra elements
+	SliceMergeKey                        // Merge map elements sharing the same identity field value
+)
+
+// MergeOption defines a function type for applying options to a merge operation.
+type MergeOption func(*mergeOptions)
+
+// mergeOptions holds the slice strategies applied by a merge operation.
+type mergeOptions struct {
+	strategy SliceStrategy            // Strategy for slices without a key-specific rule
+	keyField string                   // Identity field used by SliceMergeKey
+	keys     map[string]*mergeOptions // Key-specific rules, by dotted key
+}
+
+// Codec encodes and decodes configuration data for a single file format.
+// Built-in codecs exist for JSON, YAML and TOML; custom codecs can be added
+// with RegisterCodec or WithCodec.
+type Codec interface {
+	// Decode parses raw file content into a configuration map.
+	Decode(content []byte) (map[string]interface{}, error)
+	// Encode serializes a configuration map into raw file content.
+	Encode(data map[string]interface{}) ([]byte, error)
+	// Extensions returns the file extensions handled by the codec, without the leading dot.
+	Extensions() []string
+}
+
+// Resolver resolves secret references such as file:///run/secrets/db_pw in configuration
+// values. Resolvers are registered by URI scheme with RegisterResolver or WithResolver, and
+// built-in resolvers exist for the file and env schemes.
+type Resolver interface {
+	// Resolve returns the value referenced by ref, the reference without its scheme and
+	// "://", e.g. "/run/secrets/db_pw" for file:///run/secrets/db_pw.
+	Resolve(ref string) (string, error)
+}
+
+// Getter is implemented by Manager and ThreadSafeManager, and gives the generic accessors
+// GetAs, GetOr and MustGet access to their values and conversions.
+type Getter interface {
+	getAs(key string, out reflect.Value) error
+}
+
+// KeyPath is a key path built from raw segments with NewKeyPath, Key and Index.
+// Its String method returns the escaped form accepted by Get, Set and Delete.
+type KeyPath struct {
+	segments []segment // Parsed path segments
+}
+
+// Match is a value matched by a query, together with its concrete dotted key.
+type Match struct {
+	Key   string      // Dotted key of the value, empty for the root
+	Value interface{} // Matched value
+}
+
+// ByteSize is a number of bytes that can be decoded from strings such as "512KiB" or "1.5GB".
+type ByteSize uint64
+
+// Secret is a sensitive configuration value, such as a password. It renders as RedactedValue
+// when printed, logged or marshaled; its content is only available through Value. Bind
+// populates Secret fields, and inline encrypted values are loaded as Secret values.
+type Secret struct {
+	value     string // Plain value
+	encrypted string // Inline encrypted value the secret was decrypted from, if any
+}
+
+// DecodeHook converts a configuration value before it is decoded into a value of the target
+// type. It returns the converted value and true, or false to leave the value unchanged.
+type DecodeHook func(value interface{}, target reflect.Type) (interface{}, bool, error)
+
+// OriginKind identifies the kind of source that set a configuration value.
+type OriginKind string
+
+// Kinds of sources recorded by the origin tracking of a Manager.
+const (
+	OriginFile    OriginKind = "file"    // Read from a file by LoadFile, LoadDir, LoadFileLayer or an include
+	OriginReader  OriginKind = "reader"  // Read from an io.Reader by Load or LoadLayer
+	OriginEnv     OriginKind = "env"     // Supplied by the environment variable overlay
+	OriginSet     OriginKind = "set"     // Stored by Set
+	OriginMerge   OriginKind = "merge"   // Merged by Merge or MergeMap
+	OriginDefault OriginKind = "default" // Supplied as a map by AddLayer, typically defaults
+)
+
+// Source describes where a configuration value came from.
+type Source struct {
+	Kind   OriginKind  // Kind of source that set the value
+	Layer  string      // Name of the layer holding the value
+	File   string      // File the value was read from, for OriginFile
+	Line   int         // 1-based line of the key in the file or reader, 0 if unknown
+	Column int         // 1-based column of the key in the file or reader, 0 if unknown
+	EnvVar string      // Name of the environment variable, for OriginEnv
+	Value  interface{} // Value set by the source
+}
+
+// position is the location of a key in a configuration document.
+type position struct {
+	line   int // 1-based line number
+	column int // 1-based column number
+}
+
+// LoadDirOption defines a function type for applying options to LoadDir.
+type LoadDirOption func(*loadDirOptions)
+
+// loadDirOptions holds the options applied by LoadDir.
+type loadDirOptions struct {
+	ignore    []string // Additional file name patterns to skip
+	recursive bool     // Whether subdirectories are loaded too
+}
+
+// LockOption defines a function type for applying options to the file locks of Update and View.
+type LockOption func(*lockOptions)
+
+// lockOptions holds the options applied when locking a file.
+type lockOptions struct {
+	timeout  time.Duration // How long to wait for the lock, zero to wait indefinitely
+	staleAge time.Duration // Age after which a lock file is considered abandoned
+}
+
+// loadedFile is a configuration file read by LoadFile, merged with the files it includes.
+type loadedFile struct {
+	data     map[string]interface{} // Configuration data, including the included files
+	origins  map[string][]Source    // Sources that set each leaf key of data, oldest first
+	format   Format                 // Format of the file
+	path     string                 // Resolved path of the file
+	content  []byte                 // Raw content of the file itself
+	includes *fileIncludes          // Files included through IncludeKey, nil without includes
+}
+
+// fileIncludes records how a loaded file was assembled from the files it includes, so that
+// saving it writes back only its own content and include directive.
+type fileIncludes struct {
+	directive interface{}            // Value of the IncludeKey of the file
+	included  map[string]interface{} // Data merged from the included files
+	root      map[string]interface{} // Data of the file itself, without the included files
+}
+
 // Option defines a function type for applying configuration options to a Manager.
 type Option func(*Manager)
 
@@ -24,14 +194,159 @@ type Manager struct {
 	data          map[string]interface{} // Configuration data
 	filePath      string                 // Path to the configuration file
 	fileFormat    Format                 // Format of the configuration file
+	yamlDoc       *yaml.Node             // YAML document last loaded, kept to preserve its layout on save
 	caseSensitive bool                   // Whether keys are case-sensitive
+	layers        []*layer               // Named layers, lowest precedence first
+	subscriptions []*subscription        // Subscriptions to changes of key prefixes
+	deferNotify   bool                   // Whether subscription callbacks are queued instead of called
+	pending       []func()               // Subscription callbacks queued while deferNotify is set
+	schema        *Schema                // Schema the configuration data must satisfy
+	decodeHooks   []DecodeHook           // Conversion hooks applied by Bind
+	timeLayouts   []string               // Additional layouts used to parse time values
+	interpolation bool                   // Whether ${...} references are expanded by Get
+	origins       map[string][]Source    // Sources that set each leaf key of data, oldest first, by dotted key
+	watchInterval time.Duration          // Interval between checks of the watched file
+	watchDebounce time.Duration          // Quiet period required before reloading a changed file
+	codecs        map[Format]Codec       // Codecs registered on this Manager only
+	extensions    map[string]Format      // File extensions of codecs registered on this Manager
+
+	includes *fileIncludes // Files included by the file last loaded, nil without includes
+
+	fileDigest     []byte // SHA-256 digest of the file content last loaded or saved
+	overwriteCheck bool   // Whether saving refuses to overwrite a file changed on disk
+	backup         bool   // Whether saving keeps the previous file content as a .bak file
+
+	sensitiveKeys []string // Patterns of keys whose values are redacted
+	encryptionKey []byte   // AES-256 key used to decrypt and encrypt inline encrypted values
+
+	strictConversion bool // Whether conversions that lose information are rejected
+
+	references     bool                // Whether secret references are resolved by Get
+	referenceTTL   time.Duration       // How long resolved references are cached
+	referenceCache *referenceCache     // Cache of resolved references
+	resolvers      map[string]Resolver // Resolvers registered on this Manager only, by scheme
+
+	envEnabled     bool              // Whether environment variables override configuration data
+	envPrefix      string            // Prefix of environment variable names
+	envSeparator   string            // Separator between the prefix and key segments
+	envUpperCase   bool              // Whether environment variable names are upper-cased
+	envKeyReplacer *strings.Replacer // Replacer applied to keys before mapping them to variable names
+}
+
+// layer is a named source of configuration data in a Manager's layer stack.
+type layer struct {
+	name    string                 // Name of the layer
+	data    map[string]interface{} // Configuration data supplied by the layer
+	origins map[string][]Source    // Sources that set each leaf key of data, oldest first, by dotted key
+}
+
+// Change describes a key whose value was added, removed or modified.
+type Change struct {
+	Key string      // Dotted key of the changed value
+	Old interface{} // Value before the change, nil if the key was added
+	New interface{} // Value after the change, nil if the key was removed
+}
+
+// Diff lists the leaf keys changed by a single operation, sorted by key.
+type Diff struct {
+	Added    []Change // Keys that did not exist before the operation
+	Removed  []Change // Keys that no longer exist after the operation
+	Modified []Change // Keys whose value changed
+}
 
+// referenceCache caches resolved secret references. It has its own mutex, as references
+// are resolved by concurrent reads.
+type referenceCache struct {
+	mu      sync.Mutex                 // Mutex guarding entries
+	entries map[string]cachedReference // Resolved values, by reference
+}
+
+// cachedReference is a resolved secret reference and its expiry time.
+type cachedReference struct {
+	value   string    // Resolved value
+	expires time.Time // Time after which the value must be resolved again
+}
+
+// subscription is a handler registered for changes under a key prefix.
+type subscription struct {
+	prefix  string          // Dotted key prefix, empty for the whole configuration
+	handler func(diff Diff) // Handler called with the changes under the prefix
 }
 
 // ThreadSafeManager provides thread-safe access to a Manager instance.
 type ThreadSafeManager struct {
 	mu      *sync.RWMutex // Mutex for concurrent access control
 	manager *Manager      // Underlying Manager instance
+
+	handlersMu     sync.Mutex                              // Mutex guarding the registered handlers
+	changeHandlers []func(old, new map[string]interface{}) // Handlers called after a reload
+	errorHandlers  []func(err error)                       // Handlers called when a reload fails
+}
+
+// Schema is a compiled JSON Schema used to validate configuration data.
+// It supports a subset of draft 2020-12: type, enum, const, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern, properties,
+// required, additionalProperties, items, minItems and maxItems.
+type Schema struct {
+	types                []string           // Allowed JSON types, empty for any type
+	enum                 []interface{}      // Allowed values, nil for any value
+	constValue           interface{}        // Required value, if hasConst is set
+	hasConst             bool               // Whether constValue is set
+	minimum              *float64           // Inclusive lower bound for numbers
+	maximum              *float64           // Inclusive upper bound for numbers
+	exclusiveMinimum     *float64           // Exclusive lower bound for numbers
+	exclusiveMaximum     *float64           // Exclusive upper bound for numbers
+	minLength            *int               // Minimum length of strings
+	maxLength            *int               // Maximum length of strings
+	pattern              *regexp.Regexp     // Pattern strings must match
+	properties           map[string]*Schema // Schemas of object properties
+	required             []string           // Required object properties
+	additionalProperties *Schema            // Schema of properties not listed in properties
+	noAdditional         bool               // Whether properties not listed in properties are rejected
+	items                *Schema            // Schema of array items
+	minItems             *int               // Minimum number of array items
+	maxItems             *int               // Maximum number of array items
+	writeOnly            bool               // Whether values are sensitive and redacted
+}
+
+// Violation describes a single value that does not satisfy a Schema.
+type Violation struct {
+	Key     string // Dotted key of the invalid value, empty for the root
+	Message string // Description of the violated constraint
+}
+
+// ValidationError reports every violation found while validating configuration data.
+type ValidationError struct {
+	Violations []Violation // Violations, in key order
+}
+
+// Error implements the error interface for ValidationError.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		if v.Key == "" {
+			messages[i] = v.Message
+			continue
+		}
+		messages[i] = fmt.Sprintf("'%s': %s", v.Key, v.Message)
+	}
+	return fmt.Sprintf("%d violation(s): %s", len(e.Violations), strings.Join(messages, "; "))
+}
+
+// ConversionError reports a configuration value that cannot be converted to the requested
+// type. It is wrapped in a ConfigError giving the key of the value.
+type ConversionError struct {
+	From   reflect.Type // Type of the configuration value
+	To     reflect.Type // Type the value was converted to
+	Reason string       // Why the value cannot be converted, empty if the types are incompatible
+}
+
+// Error implements the error interface for ConversionError.
+func (e *ConversionError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("cannot convert %s to %s", e.From, e.To)
+	}
+	return fmt.Sprintf("cannot convert %s to %s: %s", e.From, e.To, e.Reason)
 }
 
 // ConfigError represents an error that occurred during configuration operations.
@@ -3,14 +3,17 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // detectFileFormat determines the file format based on its extension.
+// The lookup function maps a lower-cased extension without its leading dot to a format.
 // Returns the file format (Format) or an error if the format is not supported.
-func detectFileFormat(filePath string) (Format, error) {
+func detectFileFormat(filePath string, lookup func(ext string) (Format, bool)) (Format, error) {
 	if filePath == "" {
 		return "", errors.New("empty file path")
 	}
@@ -22,19 +25,94 @@ func detectFileFormat(filePath string) (Format, error) {
 
 	extension = extension[1:]
 
-	formatMap := map[string]Format{
-		"json": FormatJSON,
-		"yaml": FormatYAML,
-		"yml":  FormatYAML,
-	}
-
-	if format, ok := formatMap[extension]; ok {
+	if format, ok := lookup(extension); ok {
 		return format, nil
 	}
 
 	return "", fmt.Errorf("unsupported file format: %s", extension)
 }
 
+// openFile resolves a configuration file path, detects its format and opens it for reading.
+// The caller is responsible for closing the returned file.
+func (m *Manager) openFile(filePath string) (*os.File, Format, error) {
+	resolvedPath, err := resolvePath(filePath)
+	if err != nil {
+		return nil, "", &ConfigError{
+			Operation: "resolve path",
+			Err:       err,
+		}
+	}
+
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return nil, "", &ConfigError{
+			Operation: "stat file",
+			Err:       err,
+		}
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, "", &ConfigError{
+			Operation: "check file",
+			Err:       fmt.Errorf("file '%s' is not a regular file", filePath),
+		}
+	}
+
+	format, err := detectFileFormat(resolvedPath, m.formatForExtension)
+	if err != nil {
+		return nil, "", &ConfigError{
+			Operation: "detect file format",
+			Err:       err,
+		}
+	}
+
+	file, err := os.Open(resolvedPath)
+	if err != nil {
+		return nil, "", &ConfigError{
+			Operation: "open file",
+			Err:       err,
+		}
+	}
+
+	return file, format, nil
+}
+
+// decode reads all content from r and parses it with the codec registered for format.
+// Inline encrypted values are decrypted into Secret values. It also returns the origin of
+// each leaf key, based on source.
+func (m *Manager) decode(r io.Reader, format Format, source Source) (map[string]interface{}, map[string][]Source, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, &ConfigError{
+			Operation: "read file content",
+			Err:       err,
+		}
+	}
+
+	codec, err := m.codec(format)
+	if err != nil {
+		return nil, nil, &ConfigError{
+			Operation: "parse",
+			Err:       err,
+		}
+	}
+
+	data, err := codec.Decode(content)
+	if err != nil {
+		return nil, nil, &ConfigError{
+			Operation: "parse",
+			Err:       err,
+		}
+	}
+
+	origins := decodeOrigins(codec, content, data, source)
+	if _, err := m.decryptValues(data, ""); err != nil {
+		return nil, nil, err
+	}
+
+	return data, origins, nil
+}
+
 // resolvePath processes a file path by expanding environment variables and converting to absolute path.
 // It supports both $VAR and ${VAR} formats for environment variables.
 // Returns the resolved absolute path or an error if the path cannot be resolved.
@@ -67,73 +145,51 @@ func resolvePath(filePath string) (string, error) {
 	return filePath, nil
 }
 
-// getNestedMap traverses a nested map using a dot-separated path and retrieves the target map and key.
-func getNestedMap(data map[string]interface{}, path string, caseSensitive bool) (map[string]interface{}, string, error) {
-	keys := strings.Split(path, ".")
-	if len(keys) == 0 {
-		return nil, "", errors.New("invalid path")
+// findValue retrieves the value stored under a key path in data. Keys containing
+// wildcards return a []interface{} holding every matching value.
+// Returns a ConfigError if the key is invalid or cannot be found.
+func findValue(data map[string]interface{}, key string, caseSensitive bool) (interface{}, error) {
+	segments, err := parseKey(key)
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "parse key",
+			Key:       key,
+			Err:       err,
+		}
 	}
 
-	lastIndex := len(keys) - 1
-	lastKey := keys[lastIndex]
-	current := data
+	var matches []interface{}
+	err = lookupPath(data, segments, "", caseSensitive, func(_ string, value interface{}) {
+		matches = append(matches, value)
+	})
 
-	if lastIndex == 0 {
-		return current, lastKey, nil
+	if hasWildcard(segments) && len(matches) > 0 {
+		return matches, nil
 	}
 
-	for i := 0; i < lastIndex; i++ {
-		key := keys[i]
-
-		if !caseSensitive {
-			found := false
-			for k := range current {
-				if strings.EqualFold(k, key) {
-					key = k
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil, "", fmt.Errorf("key '%s' not found in path '%s'", key, path)
-			}
-		}
-
-		val, exists := current[key]
-		if !exists {
-			return nil, "", fmt.Errorf("key '%s' not found in path '%s'", key, path)
-		}
-
-		nestedMap, ok := val.(map[string]interface{})
-		if !ok {
-			iFaceMap, isIFaceMap := val.(map[interface{}]interface{})
-			if !isIFaceMap {
-				return nil, "", fmt.Errorf("key '%s' is not a map in path '%s'", key, path)
-			}
-
-			nestedMap = make(map[string]interface{})
-			for k, v := range iFaceMap {
-				strKey, ok := k.(string)
-				if !ok {
-					return nil, "", fmt.Errorf("key '%s' is not a string in path '%s'", key, path)
-				}
-				nestedMap[strKey] = v
-			}
-		}
-
-		current = nestedMap
+	if err == nil && len(matches) == 0 {
+		err = fmt.Errorf("key '%s' not found", key)
 	}
 
-	if !caseSensitive {
-		for k := range current {
-			if strings.EqualFold(k, lastKey) {
-				lastKey = k
-				break
-			}
+	if err != nil {
+		return nil, &ConfigError{
+			Operation: "get value",
+			Key:       key,
+			Err:       err,
 		}
 	}
 
-	return current, lastKey, nil
+	return matches[0], nil
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // transformMapKeys recursively converts all map keys to strings within a nested structure.
@@ -189,3 +245,58 @@ func transformMapKeys(v interface{}) interface{} {
 		return v
 	}
 }
+
+// transformTOMLValues recursively normalizes data decoded from TOML so it matches the
+// shapes produced by the JSON and YAML decoders.
+//
+// The function handles:
+// - []map[string]interface{} (arrays of tables) -> converted to []interface{}
+// - map[string]interface{} -> recursively transform values
+// - []interface{} -> recursively transform each element
+// - other types (including time.Time for TOML datetimes) -> returned as-is
+//
+// Returns the normalized structure.
+func transformTOMLValues(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[k] = transformTOMLValues(val)
+		}
+		return m
+	case []map[string]interface{}:
+		result := make([]interface{}, len(x))
+		for i, val := range x {
+			result[i] = transformTOMLValues(val)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i, val := range x {
+			result[i] = transformTOMLValues(val)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// copyValue returns a deep copy of maps and slices within v. Other values are returned as-is.
+func copyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[k] = copyValue(val)
+		}
+		return m
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i, val := range x {
+			result[i] = copyValue(val)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// WithWatchInterval sets how often Watch checks the configuration file for changes.
+// Defaults to one second.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		if interval > 0 {
+			m.watchInterval = interval
+		}
+	}
+}
+
+// WithWatchDebounce sets how long the configuration file must stay unchanged before Watch
+// reloads it, so that bursts of writes trigger a single reload. Defaults to 100ms.
+func WithWatchDebounce(debounce time.Duration) Option {
+	return func(m *Manager) {
+		if debounce >= 0 {
+			m.watchDebounce = debounce
+		}
+	}
+}
+
+// OnChange registers a handler called after the configuration file was reloaded.
+// The handler receives copies of the configuration data before and after the reload.
+func (t *ThreadSafeManager) OnChange(handler func(old, new map[string]interface{})) {
+	t.handlersMu.Lock()
+	defer t.handlersMu.Unlock()
+	t.changeHandlers = append(t.changeHandlers, handler)
+}
+
+// OnWatchError registers a handler called when Watch fails to reload the configuration file.
+// The previous configuration data stays in place when a reload fails.
+func (t *ThreadSafeManager) OnWatchError(handler func(err error)) {
+	t.handlersMu.Lock()
+	defer t.handlersMu.Unlock()
+	t.errorHandlers = append(t.errorHandlers, handler)
+}
+
+// Reload re-reads the file last loaded with LoadFile or saved with SaveToFile and atomically
+// replaces the configuration data. If the file cannot be read, parsed or validated, the
+// current data is kept and an error is returned. The file is read under the manager's lock,
+// so that it cannot interleave with a concurrent save or load.
+func (t *ThreadSafeManager) Reload() error {
+	var old map[string]interface{}
+	var loaded *loadedFile
+	var err error
+	t.update(func() {
+		if t.manager.filePath == "" {
+			err = &ConfigError{
+				Operation: "reload",
+				Err:       errors.New("file path not set"),
+			}
+			return
+		}
+
+		if loaded, err = t.manager.readFile(t.manager.filePath, nil); err != nil {
+			return
+		}
+		if err = t.manager.validateData(loaded.data); err != nil {
+			return
+		}
+
+		defer t.manager.track()()
+
+		old = copyValue(t.manager.data).(map[string]interface{})
+		t.manager.setFile(loaded)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	t.handlersMu.Lock()
+	handlers := append([]func(old, new map[string]interface{}){}, t.changeHandlers...)
+	t.handlersMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, copyValue(loaded.data).(map[string]interface{}))
+	}
+
+	return nil
+}
+
+// Watch starts watching the file last loaded with LoadFile and reloads it when it changes,
+// including when an editor replaces the file by renaming a new file over it. When LoadFile
+// or SaveToFile switches to another file, Watch follows it. Watching stops when ctx is done.
+// Watch returns an error if no file has been loaded.
+func (t *ThreadSafeManager) Watch(ctx context.Context) error {
+	t.mu.RLock()
+	filePath := t.manager.filePath
+	interval := t.manager.watchInterval
+	debounce := t.manager.watchDebounce
+	t.mu.RUnlock()
+
+	if filePath == "" {
+		return &ConfigError{
+			Operation: "watch",
+			Err:       errors.New("file path not set"),
+		}
+	}
+
+	last, err := os.Stat(filePath)
+	if err != nil {
+		return &ConfigError{
+			Operation: "stat file",
+			Err:       err,
+		}
+	}
+
+	go t.watch(ctx, filePath, last, interval, debounce)
+	return nil
+}
+
+// watch polls the current file of the manager for changes until ctx is done.
+func (t *ThreadSafeManager) watch(ctx context.Context, filePath string, last os.FileInfo, interval, debounce time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := false
+	var changedAt time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		t.mu.RLock()
+		watched := t.manager.filePath
+		t.mu.RUnlock()
+
+		if watched != filePath {
+			// The manager switched files: watch the new one from its current state.
+			current, err := os.Stat(watched)
+			if err != nil {
+				continue
+			}
+			filePath, last, pending = watched, current, false
+			continue
+		}
+
+		current, err := os.Stat(filePath)
+		if err != nil {
+			// The file may briefly disappear while an editor replaces it.
+			continue
+		}
+
+		if fileChanged(last, current) {
+			last = current
+			pending = true
+			changedAt = time.Now()
+			if debounce > 0 {
+				continue
+			}
+		}
+
+		if !pending || time.Since(changedAt) < debounce {
+			continue
+		}
+
+		pending = false
+		if err := t.Reload(); err != nil {
+			t.handlersMu.Lock()
+			handlers := append([]func(err error){}, t.errorHandlers...)
+			t.handlersMu.Unlock()
+
+			for _, handler := range handlers {
+				handler(err)
+			}
+		}
+	}
+}
+
+// fileChanged reports whether a file was modified or replaced between two stats.
+func fileChanged(last, current os.FileInfo) bool {
+	return !os.SameFile(last, current) ||
+		!last.ModTime().Equal(current.ModTime()) ||
+		last.Size() != current.Size()
+}
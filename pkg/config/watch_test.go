@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestThreadSafeManager_Watch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0644))
+
+	m := New(WithWatchInterval(10*time.Millisecond), WithWatchDebounce(30*time.Millisecond))
+	require.NoError(t, m.LoadFile(path))
+	ts := m.ThreadSafe()
+
+	changes := make(chan [2]map[string]interface{}, 4)
+	errs := make(chan error, 4)
+	ts.OnChange(func(old, new map[string]interface{}) {
+		changes <- [2]map[string]interface{}{old, new}
+	})
+	ts.OnWatchError(func(err error) {
+		errs <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, ts.Watch(ctx))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 9090}`), 0644))
+	select {
+	case change := <-changes:
+		assert.Equal(t, float64(8080), change[0]["port"])
+		assert.Equal(t, float64(9090), change[1]["port"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification after writing the file")
+	}
+
+	tmp := filepath.Join(dir, "config.json.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte(`{"port": 7070}`), 0644))
+	require.NoError(t, os.Rename(tmp, path))
+	select {
+	case change := <-changes:
+		assert.Equal(t, float64(7070), change[1]["port"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification after replacing the file")
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": `), 0644))
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a watch error after writing an invalid file")
+	}
+
+	port, err := ts.GetInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, 7070, port, "the previous configuration should stay in place after a failed reload")
+
+	assert.Error(t, New().ThreadSafe().Watch(ctx), "Watch should fail without a loaded file")
+}
+
+func TestThreadSafeManager_Reload_CopiesOldData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"db": {"host": "a"}}`), 0644))
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	ts := m.ThreadSafe()
+	before := ts.Data()
+
+	ts.OnChange(func(old, new map[string]interface{}) {
+		old["db"].(map[string]interface{})["host"] = "mutated"
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"db": {"host": "b"}}`), 0644))
+	require.NoError(t, ts.Reload())
+
+	assert.Equal(t, "a", before["db"].(map[string]interface{})["host"], "handlers should receive a copy of the old data")
+}
+
+func TestThreadSafeManager_Watch_FollowsFile(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+	require.NoError(t, os.WriteFile(first, []byte(`{"port": 8080}`), 0644))
+
+	m := New(WithWatchInterval(10*time.Millisecond), WithWatchDebounce(0))
+	require.NoError(t, m.LoadFile(first))
+	ts := m.ThreadSafe()
+
+	changes := make(chan map[string]interface{}, 4)
+	ts.OnChange(func(_, new map[string]interface{}) {
+		changes <- new
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, ts.Watch(ctx))
+
+	require.NoError(t, ts.SaveToFile(second, FormatJSON))
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(second, []byte(`{"port": 9090}`), 0644))
+	select {
+	case data := <-changes:
+		assert.Equal(t, float64(9090), data["port"], "Watch should follow the file the manager switched to")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification after writing the new file")
+	}
+}
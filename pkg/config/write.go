@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrFileChanged is returned by Save and SaveToFile when the file was modified on disk
+// since it was loaded or last saved. Reload the file before saving again.
+var ErrFileChanged = errors.New("file changed on disk since it was loaded")
+
+// defaultFileMode is the permission of configuration files created by SaveToFile.
+const defaultFileMode os.FileMode = 0644
+
+// WithOverwriteCheck controls whether SaveToFile refuses to overwrite the loaded file when it
+// was changed on disk since it was loaded or last saved. Defaults to true.
+func WithOverwriteCheck(enabled bool) Option {
+	return func(m *Manager) {
+		m.overwriteCheck = enabled
+	}
+}
+
+// WithBackup makes SaveToFile keep the previous content of a file it overwrites in a file
+// with the same name and a .bak extension appended.
+func WithBackup(enabled bool) Option {
+	return func(m *Manager) {
+		m.backup = enabled
+	}
+}
+
+// writeFile replaces the file at path with content. Symbolic links are followed, so the
+// file they point to is replaced. The previous content is checked against the digest of
+// the loaded file and backed up according to the manager's options.
+func (m *Manager) writeFile(path string, content []byte) error {
+	target := path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		target = resolved
+	}
+
+	previous, err := os.ReadFile(target)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		return writeFileAtomic(target, content)
+	}
+
+	if m.overwriteCheck && samePath(path, m.filePath) && m.fileDigest != nil && !bytes.Equal(digest(previous), m.fileDigest) {
+		return ErrFileChanged
+	}
+
+	if m.backup {
+		if err := writeFileAtomic(target+".bak", previous); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(target, content)
+}
+
+// samePath reports whether two paths name the same file, comparing their absolute, cleaned
+// forms and, when both exist, the files their symbolic links point to.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	if absA == absB {
+		return true
+	}
+
+	resolvedA, errA := filepath.EvalSymlinks(absA)
+	resolvedB, errB := filepath.EvalSymlinks(absB)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
+
+// writeFileAtomic writes content to a temporary file in the directory of path, flushes it
+// to disk and renames it over path, so that readers and crashes never observe a partially
+// written file. An existing file keeps its permissions and, where permitted, its owner.
+func writeFileAtomic(path string, content []byte) (err error) {
+	mode := defaultFileMode
+	info, statErr := os.Stat(path)
+	if statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(content); err != nil {
+		return err
+	}
+
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+
+	if statErr == nil {
+		if uid, gid, ok := fileOwner(info); ok {
+			_ = tmp.Chown(uid, gid)
+		}
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// digest returns the SHA-256 digest of content.
+func digest(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// fileOwner reports that file ownership is not available on this platform.
+func fileOwner(os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// syncDir does nothing, as directories cannot be flushed on this platform.
+func syncDir(string) error {
+	return nil
+}
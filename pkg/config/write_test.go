@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_SaveToFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0600))
+
+	m := New(WithBackup(true))
+	require.NoError(t, m.LoadFile(path))
+	require.NoError(t, m.Set("port", 9090))
+	require.NoError(t, m.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the file should keep its permissions")
+
+	backup, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"port": 8080}`, string(backup))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary file should be left behind")
+
+	require.NoError(t, m.Set("port", 7070))
+	require.NoError(t, m.Save(), "saving again should not be reported as a conflict")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 1}`), 0600))
+	require.NoError(t, m.Set("port", 6060))
+	err = m.Save()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFileChanged))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"port": 1}`, string(content), "a changed file should not be overwritten")
+
+	unchecked := New(WithOverwriteCheck(false))
+	require.NoError(t, unchecked.LoadFile(path))
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 2}`), 0600))
+	assert.NoError(t, unchecked.Save())
+}
+
+func TestManager_SaveToFile_OverwriteCheckPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	link := filepath.Join(dir, "link.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0600))
+	require.NoError(t, os.Symlink(path, link))
+	t.Chdir(dir)
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 1}`), 0600))
+
+	for _, target := range []string{"config.json", "./config.json", filepath.Join(dir, ".", "config.json"), link} {
+		err := m.SaveToFile(target, FormatJSON)
+		assert.True(t, errors.Is(err, ErrFileChanged), "saving to %s should detect the change", target)
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"port": 1}`, string(content))
+}
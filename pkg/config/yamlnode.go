@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"gopkg.in/yaml.v3"
+	"reflect"
+)
+
+// maxYAMLSyncPasses bounds the number of passes needed to bring a YAML document in line
+// with the configuration data. A second pass is only needed when an anchored value changed
+// while one of its aliases kept the old value.
+const maxYAMLSyncPasses = 3
+
+// isYAMLFormat reports whether format is handled by the built-in YAML codec.
+func (m *Manager) isYAMLFormat(format Format) bool {
+	codec, err := m.codec(format)
+	if err != nil {
+		return false
+	}
+
+	_, ok := codec.(yamlCodec)
+	return ok
+}
+
+// yamlDocument parses content into a YAML node tree when format is handled by the built-in
+// YAML codec, so that SaveToFile can preserve its comments, key order, styles and anchors.
+// Returns nil for other formats or when content cannot be parsed.
+func (m *Manager) yamlDocument(content []byte, format Format) *yaml.Node {
+	if !m.isYAMLFormat(format) {
+		return nil
+	}
+
+	return parseYAMLDocument(content)
+}
+
+// parseYAMLDocument parses content into a YAML document node, or returns nil if content is
+// not a YAML document.
+func parseYAMLDocument(content []byte) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || doc.Kind != yaml.DocumentNode {
+		return nil
+	}
+	return &doc
+}
+
+// encodeYAMLDocument encodes data as YAML, starting from a copy of the document it was loaded
+// from so that comments, key order, quoting styles and anchors of unchanged values are kept.
+// The document itself is left unchanged.
+func encodeYAMLDocument(doc *yaml.Node, data map[string]interface{}) ([]byte, error) {
+	synced := copyYAMLNode(doc, make(map[*yaml.Node]*yaml.Node))
+	if len(synced.Content) == 0 {
+		synced.Content = []*yaml.Node{{}}
+	}
+
+	root := synced.Content[0]
+
+	inSync := false
+	for pass := 0; pass < maxYAMLSyncPasses && !inSync; pass++ {
+		if err := syncYAMLNode(root, data); err != nil {
+			return nil, err
+		}
+		inSync = yamlNodeEquals(root, data)
+	}
+
+	if !inSync {
+		return yaml.Marshal(data)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent(doc, 4))
+	if err := enc.Encode(synced); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// syncYAMLNode updates node in place so that it decodes to value. Nodes that already decode
+// to their value are left untouched, mappings keep the order of their existing keys and get
+// new keys appended, and replaced scalars keep their quoting style, comments and anchor.
+func syncYAMLNode(node *yaml.Node, value interface{}) error {
+	if yamlNodeEquals(node, value) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if node.Kind == yaml.MappingNode && !hasYAMLMergeKey(node) {
+			return syncYAMLMapping(node, v)
+		}
+	case []interface{}:
+		if node.Kind == yaml.SequenceNode {
+			return syncYAMLSequence(node, v)
+		}
+	}
+
+	var fresh yaml.Node
+	if err := fresh.Encode(value); err != nil {
+		return err
+	}
+
+	if fresh.Kind == yaml.ScalarNode && node.Kind == yaml.ScalarNode && fresh.Tag == node.Tag {
+		fresh.Style = node.Style
+	}
+	fresh.Anchor = node.Anchor
+	fresh.HeadComment = node.HeadComment
+	fresh.LineComment = node.LineComment
+	fresh.FootComment = node.FootComment
+
+	*node = fresh
+	return nil
+}
+
+// syncYAMLMapping updates the pairs of a mapping node to match data.
+func syncYAMLMapping(node *yaml.Node, data map[string]interface{}) error {
+	content := make([]*yaml.Node, 0, len(node.Content))
+	seen := make(map[string]bool, len(data))
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		value, ok := data[keyNode.Value]
+		if !ok {
+			continue
+		}
+
+		seen[keyNode.Value] = true
+		if err := syncYAMLNode(valueNode, value); err != nil {
+			return err
+		}
+		content = append(content, keyNode, valueNode)
+	}
+
+	for _, key := range sortedKeys(data) {
+		if seen[key] {
+			continue
+		}
+
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(data[key]); err != nil {
+			return err
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		content = append(content, keyNode, valueNode)
+	}
+
+	node.Content = content
+	return nil
+}
+
+// syncYAMLSequence updates the items of a sequence node to match items.
+func syncYAMLSequence(node *yaml.Node, items []interface{}) error {
+	if len(node.Content) > len(items) {
+		node.Content = node.Content[:len(items)]
+	}
+
+	for i, item := range items {
+		if i < len(node.Content) {
+			if err := syncYAMLNode(node.Content[i], item); err != nil {
+				return err
+			}
+			continue
+		}
+
+		itemNode := &yaml.Node{}
+		if err := itemNode.Encode(item); err != nil {
+			return err
+		}
+		node.Content = append(node.Content, itemNode)
+	}
+
+	return nil
+}
+
+// yamlNodeEquals reports whether node decodes to value.
+func yamlNodeEquals(node *yaml.Node, value interface{}) bool {
+	var decoded interface{}
+	if err := node.Decode(&decoded); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(transformMapKeys(decoded), value)
+}
+
+// hasYAMLMergeKey reports whether a mapping node merges other mappings with "<<".
+func hasYAMLMergeKey(node *yaml.Node) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlIndent returns the indentation of the first nested block mapping of a document, or
+// fallback if the document has none.
+func yamlIndent(node *yaml.Node, fallback int) int {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if valueNode.Kind == yaml.MappingNode && valueNode.Style&yaml.FlowStyle == 0 && len(valueNode.Content) > 0 {
+				if indent := valueNode.Content[0].Column - keyNode.Column; indent > 0 {
+					return indent
+				}
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		if indent := yamlIndent(child, 0); indent > 0 {
+			return indent
+		}
+	}
+
+	return fallback
+}
+
+// copyYAMLNode returns a deep copy of a YAML node tree. copies maps the nodes already copied
+// to their copy, so aliases keep pointing to the copy of their anchored node.
+func copyYAMLNode(node *yaml.Node, copies map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	if c, ok := copies[node]; ok {
+		return c
+	}
+
+	c := *node
+	copies[node] = &c
+
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyYAMLNode(child, copies)
+	}
+	c.Alias = copyYAMLNode(node.Alias, copies)
+
+	return &c
+}
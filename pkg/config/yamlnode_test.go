@@ -0,0 +1,69 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_SaveToFilePreservesYAMLLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Application settings
+server:
+  # Public host name
+  host: "localhost" # quoted on purpose
+  port: 8080
+defaults: &defaults
+  timeout: 30
+upstream:
+  settings: *defaults
+  name: 'api'
+features:
+  - search
+  - login
+`), 0644))
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	require.NoError(t, m.Set("server.host", "example.com"))
+	require.NoError(t, m.Set("server.tls", true))
+	require.NoError(t, m.Delete("features[1]"))
+	require.NoError(t, m.Save())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Application settings
+server:
+  # Public host name
+  host: "example.com" # quoted on purpose
+  port: 8080
+  tls: true
+defaults: &defaults
+  timeout: 30
+upstream:
+  settings: *defaults
+  name: 'api'
+features:
+  - search
+`, string(content))
+
+	reloaded := New()
+	require.NoError(t, reloaded.LoadFile(path))
+	assert.Equal(t, m.Data(), reloaded.Data())
+}
+
+func TestManager_SaveToFileBreaksChangedAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("base: &base 1\ncopy: *base\n"), 0644))
+
+	m := New()
+	require.NoError(t, m.LoadFile(path))
+	require.NoError(t, m.Set("base", 2))
+	require.NoError(t, m.Save())
+
+	reloaded := New()
+	require.NoError(t, reloaded.LoadFile(path))
+	assert.Equal(t, map[string]interface{}{"base": 2, "copy": 1}, reloaded.Data())
+}